@@ -0,0 +1,96 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Number of attempts to update attribute that is changed concurrently.
+const readModifyWriteAttempts = 3
+
+// Reports whether account is disabled. userAccountControl attribute must be requested.
+func (u *User) IsDisabled() bool {
+	return u.UserAccountControl().Has(UACAccountDisable)
+}
+
+// Reports whether account password never expires. userAccountControl attribute must be requested.
+func (u *User) PasswordNeverExpires() bool {
+	return u.UserAccountControl().Has(UACDontExpirePassword)
+}
+
+// Reports whether smart card is required for interactive logon. userAccountControl attribute must be requested.
+func (u *User) SmartcardRequired() bool {
+	return u.UserAccountControl().Has(UACSmartcardRequired)
+}
+
+// Reports whether Kerberos pre-authentication isn't required for the account.
+// userAccountControl attribute must be requested.
+func (u *User) DontRequirePreauth() bool {
+	return u.UserAccountControl().Has(UACDontRequirePreauth)
+}
+
+// Enables user account.
+func (cl *Client) EnableUser(dn string) error {
+	return cl.EnableUserContext(context.Background(), dn)
+}
+
+// Context-aware version of EnableUser.
+func (cl *Client) EnableUserContext(ctx context.Context, dn string) error {
+	return cl.SetUserAccountControlFlagsContext(ctx, dn, 0, UACAccountDisable)
+}
+
+// Disables user account.
+func (cl *Client) DisableUser(dn string) error {
+	return cl.DisableUserContext(context.Background(), dn)
+}
+
+// Context-aware version of DisableUser.
+func (cl *Client) DisableUserContext(ctx context.Context, dn string) error {
+	return cl.SetUserAccountControlFlagsContext(ctx, dn, UACAccountDisable, 0)
+}
+
+// Sets and clears provided userAccountControl flags of user account. Flags in both sets are cleared.
+// Other flags are kept: current value is read and replaced only if it wasn't changed concurrently.
+func (cl *Client) SetUserAccountControlFlags(dn string, set, clear UACFlags) error {
+	return cl.SetUserAccountControlFlagsContext(context.Background(), dn, set, clear)
+}
+
+// Context-aware version of SetUserAccountControlFlags.
+func (cl *Client) SetUserAccountControlFlagsContext(ctx context.Context, dn string, set, clear UACFlags) error {
+	for attempt := 1; ; attempt++ {
+		entry, err := cl.readEntry(ctx, dn, []string{"userAccountControl"})
+		if err != nil {
+			return fmt.Errorf("can't get account: %w", err)
+		}
+		if entry == nil {
+			return fmt.Errorf("account '%s' not found", dn)
+		}
+		raw := entry.GetAttributeValue("userAccountControl")
+		current, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid userAccountControl value '%s'", raw)
+		}
+
+		updated := (UACFlags(current) | set) &^ clear
+		if updated == UACFlags(current) {
+			return nil
+		}
+		err = cl.replaceValue(ctx, dn, "userAccountControl", raw, strconv.FormatUint(uint64(updated), 10))
+		if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) || attempt >= readModifyWriteAttempts {
+			return err
+		}
+		cl.logger.Debugf("userAccountControl of '%s' was changed concurrently, retrying", dn)
+	}
+}
+
+// Replaces attribute value with delete of the old value and add of the new one in a single request.
+// Request fails with 'No Such Attribute' error if the old value was changed concurrently.
+func (cl *Client) replaceValue(ctx context.Context, dn, attribute, old, new string) error {
+	req := ldap.NewModifyRequest(dn, nil)
+	req.Delete(attribute, []string{old})
+	req.Add(attribute, []string{new})
+	return cl.modifyRequest(ctx, req)
+}
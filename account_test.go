@@ -0,0 +1,112 @@
+package adc
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock connection that mimics concurrent change of userAccountControl before the first modify requests.
+// Concurrent change toggles smart card required flag.
+type racingConn struct {
+	*mockClient
+	races *atomic.Int32
+}
+
+func (c *racingConn) Modify(req *ldap.ModifyRequest) error {
+	if c.races.Add(-1) >= 0 {
+		for _, a := range c.getEntryByDn(req.DN).Attributes {
+			if a.Name == "userAccountControl" {
+				flags, _ := strconv.ParseUint(a.Values[0], 10, 32)
+				a.Values = []string{strconv.FormatUint(flags^uint64(UACSmartcardRequired), 10)}
+			}
+		}
+	}
+	return c.mockClient.Modify(req)
+}
+
+func connectWithRacingConn(t *testing.T, cl *Client, races int32) {
+	counter := &atomic.Int32{}
+	counter.Store(races)
+	p, err := newPool(context.Background(), cl.Config.Pool, func(ctx context.Context) (ldap.Client, error) {
+		conn, err := mockConnection()
+		return &racingConn{mockClient: conn, races: counter}, err
+	})
+	require.NoError(t, err)
+	cl.mu.Lock()
+	cl.pool = p
+	cl.mu.Unlock()
+}
+
+func getUser1UAC(t *testing.T, cl *Client) UACFlags {
+	user, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+	require.NoError(t, err)
+	return user.UserAccountControl()
+}
+
+func Test_User_accountControlHelpers(t *testing.T) {
+	user := &User{Attributes: map[string]interface{}{"userAccountControl": "4260354"}}
+	require.True(t, user.IsDisabled())
+	require.True(t, user.PasswordNeverExpires())
+	require.False(t, user.SmartcardRequired())
+	require.True(t, user.DontRequirePreauth())
+
+	user = &User{Attributes: map[string]interface{}{"userAccountControl": "262656"}}
+	require.False(t, user.IsDisabled())
+	require.False(t, user.PasswordNeverExpires())
+	require.True(t, user.SmartcardRequired())
+	require.False(t, user.DontRequirePreauth())
+}
+
+func Test_Client_SetUserAccountControlFlags(t *testing.T) {
+	user1DN := "OU=user1,DC=company,DC=com"
+
+	t.Run("DisableEnable", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.Connect())
+
+		require.NoError(t, cl.DisableUser(user1DN))
+		require.Equal(t, UACNormalAccount|UACDontExpirePassword|UACAccountDisable, getUser1UAC(t, cl))
+		// Already disabled.
+		require.NoError(t, cl.DisableUser(user1DN))
+
+		require.NoError(t, cl.EnableUser(user1DN))
+		require.Equal(t, UACNormalAccount|UACDontExpirePassword, getUser1UAC(t, cl))
+	})
+	t.Run("SetAndClear", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.Connect())
+
+		require.NoError(t, cl.SetUserAccountControlFlags(user1DN, UACSmartcardRequired|UACNotDelegated, UACDontExpirePassword|UACNotDelegated))
+		require.Equal(t, UACNormalAccount|UACSmartcardRequired, getUser1UAC(t, cl))
+	})
+	t.Run("ConcurrentChange", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		connectWithRacingConn(t, cl, 1)
+
+		require.NoError(t, cl.DisableUser(user1DN))
+		require.True(t, getUser1UAC(t, cl).Has(UACAccountDisable|UACSmartcardRequired), "Concurrent change should be kept")
+
+		cl = newMockClient(&Config{Bind: validMockBind})
+		connectWithRacingConn(t, cl, readModifyWriteAttempts)
+		err := cl.DisableUser(user1DN)
+		require.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute))
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.Connect())
+		require.ErrorContains(t, cl.DisableUser("OU=unknown,DC=company,DC=com"), "not found")
+		require.ErrorContains(t, cl.DisableUser("OU=user2,DC=company,DC=com"), "invalid userAccountControl")
+	})
+	t.Run("WithContextCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.Connect())
+		require.ErrorIs(t, cl.DisableUserContext(ctx, user1DN), context.Canceled)
+	})
+}
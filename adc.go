@@ -6,7 +6,9 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
-	"strings"
+	"net"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-ldap/ldap/v3"
@@ -14,18 +16,36 @@ import (
 )
 
 // Active Direcotry client.
+//
+// Client is safe for concurrent use once created and configured. Operations may run concurrently with
+// Connect, Reconnect and Disconnect: connections state is swapped atomically, and operations in flight
+// finish on the connections they already checked out. Config must not be modified after Connect.
 type Client struct {
-	Config   *Config
-	ldap     ldap.Client
-	logger   Logger
-	mockMode bool
+	Config *Config
+
+	mu          sync.RWMutex
+	pool        *pool
+	lastErr     error
+	stopMonitor context.CancelFunc
+	hooks       Hooks
+
+	servers     *serverList
+	discovery   *discovery
+	credentials *credentials
+	gssapi      GSSAPIClientFactory
+	tlsBase     *tls.Config
+	logger      Logger
+	mockMode    bool
 }
 
 // Creates new client and populate provided config and options.
 func New(cfg *Config, opts ...Option) *Client {
 	cl := &Client{
-		Config: populateConfig(cfg),
-		logger: newNopLogger(),
+		Config:      populateConfig(cfg),
+		servers:     newServerList(),
+		discovery:   newDiscovery(),
+		credentials: &credentials{},
+		logger:      newNopLogger(),
 	}
 	for _, opt := range opts {
 		opt(cl)
@@ -42,58 +62,292 @@ func WithLogger(l Logger) Option {
 
 // Connects to AD server and store connection into client.
 func (cl *Client) Connect() error {
-	conn, err := cl.connect()
+	return cl.ConnectContext(context.Background())
+}
+
+// Connects to AD server and store connections pool into client.
+// Connection and bind are abandoned when provided context is done.
+func (cl *Client) ConnectContext(ctx context.Context) error {
+	return cl.openPool(ctx, false)
+}
+
+// Opens new connections pool and replaces current one with it.
+// If onlyIfActive is set, pool isn't replaced when context is done, so a stopped background
+// reconnect can't resurrect disconnected client.
+func (cl *Client) openPool(ctx context.Context, onlyIfActive bool) error {
+	// Rotated credentials are picked up on every connect.
+	cl.credentials.invalidate()
+	p, err := newPool(ctx, cl.Config.Pool, cl.dial)
 	if err != nil {
-		return fmt.Errorf("Failed to connect: %w", err)
+		return err
 	}
 
-	if cl.Config.Bind != nil {
-		if err := conn.Bind(cl.Config.Bind.DN, cl.Config.Bind.Password); err != nil {
-			return fmt.Errorf("Failed to bind: %w", err)
-		}
+	cl.mu.Lock()
+	if onlyIfActive && ctx.Err() != nil {
+		cl.mu.Unlock()
+		go cl.closePool(p)
+		return ctx.Err()
 	}
+	old := cl.pool
+	cl.pool = p
+	cl.lastErr = nil
+	cl.mu.Unlock()
 
-	cl.ldap = conn
+	if old != nil {
+		// Previous connections are drained in background to not block the caller.
+		go cl.closePool(old)
+	}
+	cl.hooks.connect(cl.CurrentServer())
 
 	return nil
 }
 
-func (cl *Client) connect() (ldap.Client, error) {
+// Removes connections pool from client and stops connection monitor. Returns removed pool.
+func (cl *Client) detachPool() *pool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	p := cl.pool
+	cl.pool = nil
+	cl.lastErr = nil
+	if cl.stopMonitor != nil {
+		cl.stopMonitor()
+		cl.stopMonitor = nil
+	}
+	return p
+}
+
+func (cl *Client) getPool() *pool {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.pool
+}
+
+// Closes pool waiting up to Config.Timeout for in-flight operations.
+func (cl *Client) closePool(p *pool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cl.Config.Timeout)
+	defer cancel()
+	return p.close(ctx)
+}
+
+// Tracks result of operation. Network error is kept until the next successful operation.
+func (cl *Client) trackError(p *pool, err error) {
+	if err != nil && !isNetworkError(err) {
+		return
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	// Result of operation on replaced pool doesn't reflect current state.
+	if cl.pool == p {
+		cl.lastErr = err
+	}
+}
+
+// Returns the last network error. Returns nil if the last operation succeeded.
+func (cl *Client) LastError() error {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.lastErr
+}
+
+// Connects to AD server and binds new connection with bind account.
+// Connection and bind are abandoned when provided context is done.
+func (cl *Client) dial(ctx context.Context) (ldap.Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		conn ldap.Client
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		conn, serverURL, err := cl.connect(ctx)
+		if err != nil {
+			ch <- result{err: fmt.Errorf("Failed to connect: %w", err)}
+			return
+		}
+		if cl.Config.Bind != nil {
+			if err := cl.bind(ctx, conn, serverURL); err != nil {
+				conn.Close()
+				ch <- result{err: fmt.Errorf("Failed to bind: %w", err)}
+				return
+			}
+		}
+		// Only pooled connections define server client is connected to.
+		cl.servers.markConnected(serverURL)
+		ch <- result{conn: conn}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			// Nobody waits for this connection anymore.
+			if r := <-ch; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// Opens new connection to one of AD servers. Plain connection is upgraded via StartTLS if configured.
+// Servers are tried according to selection strategy until connection succeeds. Returns connected server URL.
+func (cl *Client) connect(ctx context.Context) (ldap.Client, string, error) {
+	urls, err := cl.serverURLs(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	urls = cl.servers.order(urls, cl.Config.ServerSelection, cl.Config.ServerCooldown)
+
+	var errs []error
+	for _, u := range urls {
+		conn, err := cl.connectURL(u)
+		if err != nil {
+			cl.logger.Debugf("Failed to connect to '%s': %s", u, err.Error())
+			cl.servers.markFailed(u)
+			errs = append(errs, err)
+			continue
+		}
+		return conn, u, nil
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// Returns configured servers URLs followed by discovered ones if domain is provided.
+func (cl *Client) serverURLs(ctx context.Context) ([]string, error) {
+	urls := cl.Config.serverURLs()
+	if cl.Config.Domain == "" {
+		return urls, nil
+	}
+
+	if len(urls) == 1 && urls[0] == "" {
+		urls = nil
+	}
+	discovered, err := cl.discovery.lookup(ctx, cl.Config)
+	if err != nil {
+		if len(urls) == 0 {
+			return nil, err
+		}
+		cl.logger.Debugf("Using configured servers only: %s", err.Error())
+	}
+	for _, u := range discovered {
+		if !slices.Contains(urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	return urls, nil
+}
+
+// Opens new connection to provided server URL.
+func (cl *Client) connectURL(serverURL string) (ldap.Client, error) {
+	mode := cl.Config.tlsMode(serverURL)
+	if err := validateTLSMode(mode, serverURL); err != nil {
+		return nil, err
+	}
+
+	conn, err := cl.dialURL(serverURL, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cl.startTLS(conn, mode, serverURL); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (cl *Client) dialURL(serverURL string, mode TLSMode) (ldap.Client, error) {
 	if cl.mockMode {
-		return mockConnection()
+		return mockDial(serverURL)
 	}
 
-	var dialOpts []ldap.DialOpt
-	if strings.HasPrefix(cl.Config.URL, "ldaps://") {
-		dialOpts = append(
-			dialOpts, ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: cl.Config.InsecureTLS}),
-		)
+	dialOpts := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: cl.Config.DialTimeout})}
+	if mode == TLSModeLDAPS {
+		tc, err := cl.tlsConfig(serverURL)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, ldap.DialWithTLSConfig(tc))
 	}
-	return ldap.DialURL(cl.Config.URL, dialOpts...)
+	return ldap.DialURL(serverURL, dialOpts...)
+}
+
+// Returns URL of the server client's pooled connection has been opened to the last time.
+// Connections opened by CheckAuthByDN don't change it. Returns empty string if client has never connected.
+func (cl *Client) CurrentServer() string {
+	return cl.servers.currentServer()
 }
 
+// Reports whether client is connected: it has open connection that isn't closing,
+// and the last operation didn't fail with network error.
 func (cl *Client) ConnectedStatus() bool {
-	return cl.ldap != nil
+	cl.mu.RLock()
+	p, lastErr := cl.pool, cl.lastErr
+	cl.mu.RUnlock()
+	return p != nil && lastErr == nil && p.alive()
 }
 
-// Closes connection to AD.
+// Closes connections to AD.
+// Waits up to Config.Timeout for in-flight operations, then closes remaining connections, so those operations fail.
 func (cl *Client) Disconnect() error {
-	if cl.ldap == nil {
+	ctx, cancel := context.WithTimeout(context.Background(), cl.Config.Timeout)
+	defer cancel()
+	return cl.DisconnectContext(ctx)
+}
+
+// Closes connections to AD.
+// Waits for in-flight operations until provided context is done, then closes remaining connections,
+// so those operations fail, and returns context error.
+func (cl *Client) DisconnectContext(ctx context.Context) error {
+	p := cl.detachPool()
+	if p == nil {
 		return nil
 	}
-	return cl.ldap.Close()
+	err := p.close(ctx)
+	cl.hooks.disconnect(nil)
+	return err
+}
+
+// Runs provided operation on connection checked out from the pool.
+// Broken connection is dropped from the pool, so the next operation is performed on a new bound connection.
+func (cl *Client) withConn(ctx context.Context, op func(conn ldap.Client) error) error {
+	p := cl.getPool()
+	if p == nil {
+		return ErrNotConnected
+	}
+	conn, p, err := cl.getConn(ctx, p)
+	if err != nil {
+		cl.trackError(p, err)
+		return err
+	}
+	err = op(conn)
+	p.put(conn, err)
+	cl.trackError(p, err)
+	return err
+}
+
+// Checks out connection from provided pool. If the pool was closed because concurrent Connect or Reconnect
+// replaced it after it was picked up, connection is checked out from the current pool. Returns pool used.
+func (cl *Client) getConn(ctx context.Context, p *pool) (ldap.Client, *pool, error) {
+	conn, err := p.get(ctx)
+	for errors.Is(err, ErrPoolClosed) {
+		current := cl.getPool()
+		if current == nil || current == p {
+			break
+		}
+		p = current
+		conn, err = p.get(ctx)
+	}
+	return conn, p, err
 }
 
 // Checks connections to AD and tries to reconnect if the connection is lost.
 func (cl *Client) Reconnect(ctx context.Context, tickerDuration time.Duration, maxAttempts int) error {
-	_, connErr := cl.searchEntry(&ldap.SearchRequest{
-		BaseDN:       cl.Config.SearchBase,
-		Scope:        ldap.ScopeWholeSubtree,
-		DerefAliases: ldap.NeverDerefAliases,
-		TimeLimit:    int(cl.Config.Timeout.Seconds()),
-		Filter:       fmt.Sprintf(cl.Config.Users.FilterByDn, ldap.EscapeFilter(cl.Config.Bind.DN)),
-		Attributes:   []string{cl.Config.Users.IdAttribute},
-	})
+	connErr := cl.probe(ctx)
 	if connErr == nil {
 		return nil
 	}
@@ -117,26 +371,159 @@ func (cl *Client) Reconnect(ctx context.Context, tickerDuration time.Duration, m
 			}
 			attempt++
 			cl.logger.Debugf("Reconnecting to AD server. Attempt: %d", attempt)
+			cl.hooks.reconnectAttempt(attempt)
 
-			if err := cl.Disconnect(); err != nil {
-				return fmt.Errorf("failed to disconnect from the server: %w", err)
-			}
-
-			if err := cl.Connect(); err == nil {
+			// Current connections are replaced only after new ones are established.
+			err := cl.ConnectContext(ctx)
+			if err == nil {
 				cl.logger.Debug("Successfully reconneted to AD server")
 				return nil
 			}
+			cl.hooks.reconnectFailed(attempt, err)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
+// Runs provided LDAP operation and waits for its result or for the context to be done.
+// go-ldap can't cancel non-search requests, so the abandoned operation
+// still completes in background, but the caller is released immediately.
+func doContext(ctx context.Context, op func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- op() }()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Performs search request via SearchAsync, so the in-flight request is abandoned once the context is done.
+func (cl *Client) search(ctx context.Context, req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	var result *ldap.SearchResult
+	err := cl.withRetry(ctx, func(conn ldap.Client) error {
+		var err error
+		result, err = searchConn(ctx, conn, req)
+		return err
+	})
+	return result, err
+}
+
+// Performs search request on provided connection.
+func searchConn(ctx context.Context, conn ldap.Client, req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp := conn.SearchAsync(ctx, req, 0)
+	result := &ldap.SearchResult{}
+	for resp.Next() {
+		if entry := resp.Entry(); entry != nil {
+			result.Entries = append(result.Entries, entry)
+		}
+		if ref := resp.Referral(); ref != "" {
+			result.Referrals = append(result.Referrals, ref)
+		}
+		if ctrls := resp.Controls(); len(ctrls) > 0 {
+			result.Controls = append(result.Controls, ctrls...)
+		}
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	// SearchAsync silently stops on context cancellation.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Performs paged search and returns entries from all pages.
+// All pages are requested over the same connection, because AD binds paging cookie to the connection.
+func (cl *Client) searchPaged(ctx context.Context, req *ldap.SearchRequest, pageSize int) ([]*ldap.Entry, error) {
+	control := ldap.NewControlPaging(uint32(pageSize))
+	var entries []*ldap.Entry
+
+	err := cl.withRetry(ctx, func(conn ldap.Client) error {
+		// Paging restarts from the first page on retry.
+		control.SetCookie(nil)
+		entries = nil
+
+		for {
+			req.Controls = []ldap.Control{control}
+
+			sr, err := searchConn(ctx, conn, req)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, sr.Entries...)
+
+			if sr.Controls == nil {
+				return nil
+			}
+
+			pagingControl, ok := ldap.FindControl(sr.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+			if !ok {
+				return nil
+			}
+
+			if len(pagingControl.Cookie) == 0 {
+				return nil
+			}
+
+			control.SetCookie(pagingControl.Cookie)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Checks connection with search for bind account entry, or for root DSE if client has no simple bind account.
+// Failed search isn't retried.
+func (cl *Client) probe(ctx context.Context) error {
+	req := cl.rootDSERequest()
+	// Account name of other mechanisms isn't DN.
+	if cl.Config.Bind != nil && cl.Config.Bind.mechanism() == BindSimple {
+		req = &ldap.SearchRequest{
+			BaseDN:       cl.Config.SearchBase,
+			Scope:        ldap.ScopeWholeSubtree,
+			DerefAliases: ldap.NeverDerefAliases,
+			TimeLimit:    int(cl.Config.Timeout.Seconds()),
+			Filter:       fmt.Sprintf(cl.Config.Users.FilterByDn, ldap.EscapeFilter(cl.Config.Bind.DN)),
+			Attributes:   []string{cl.Config.Users.IdAttribute},
+		}
+	}
+	return cl.withConn(ctx, func(conn ldap.Client) error {
+		_, err := searchConn(ctx, conn, req)
+		return err
+	})
+}
+
+// Returns request to read root DSE. Root DSE is readable by any client, so it is a cheap liveness check.
+func (cl *Client) rootDSERequest() *ldap.SearchRequest {
+	return &ldap.SearchRequest{
+		BaseDN:       "",
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		TimeLimit:    int(cl.Config.Timeout.Seconds()),
+		Filter:       "(objectClass=*)",
+		Attributes:   []string{"defaultNamingContext"},
+	}
+}
+
 // SearchEntry Perfrom search for single ldap entry.
 // Returns nil if no entries found.
 // Returns 'ErrTooManyEntriesFound' error if entries more that one.
-func (cl *Client) searchEntry(req *ldap.SearchRequest) (*ldap.Entry, error) {
-	result, err := cl.ldap.Search(req)
+func (cl *Client) searchEntry(ctx context.Context, req *ldap.SearchRequest) (*ldap.Entry, error) {
+	result, err := cl.search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -149,39 +536,101 @@ func (cl *Client) searchEntry(req *ldap.SearchRequest) (*ldap.Entry, error) {
 	return result.Entries[0], nil
 }
 
+// Reads attributes of entry with provided DN. Returns nil if entry not found.
+func (cl *Client) readEntry(ctx context.Context, dn string, attributes []string) (*ldap.Entry, error) {
+	req := &ldap.SearchRequest{
+		BaseDN:       dn,
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		TimeLimit:    int(cl.Config.Timeout.Seconds()),
+		Filter:       "(objectClass=*)",
+		Attributes:   attributes,
+	}
+	entry, err := cl.searchEntry(ctx, req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// Reads attributes of domain object. Domain DN is taken from root DSE.
+func (cl *Client) readDomain(ctx context.Context, attributes []string) (*ldap.Entry, error) {
+	rootDSE, err := cl.searchEntry(ctx, cl.rootDSERequest())
+	if err != nil {
+		return nil, fmt.Errorf("can't get root DSE: %w", err)
+	}
+	if rootDSE == nil || rootDSE.GetAttributeValue("defaultNamingContext") == "" {
+		return nil, errors.New("domain naming context not found")
+	}
+	domainDN := rootDSE.GetAttributeValue("defaultNamingContext")
+	entry, err := cl.readEntry(ctx, domainDN, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("can't get domain: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("domain '%s' not found", domainDN)
+	}
+	return entry, nil
+}
+
 // Add request
-func (cl *Client) addRequest(req *ldap.AddRequest) error {
-	err := cl.ldap.Add(req)
-	return err
+func (cl *Client) addRequest(ctx context.Context, req *ldap.AddRequest) error {
+	return cl.withConn(ctx, func(conn ldap.Client) error {
+		return doContext(ctx, func() error { return conn.Add(req) })
+	})
 }
 
 // Delete request
-func (cl *Client) deleteRequest(req *ldap.DelRequest) error {
-	err := cl.ldap.Del(req)
-	return err
+func (cl *Client) deleteRequest(ctx context.Context, req *ldap.DelRequest) error {
+	return cl.withConn(ctx, func(conn ldap.Client) error {
+		return doContext(ctx, func() error { return conn.Del(req) })
+	})
 }
 
 // Modify request
-func (cl *Client) modifyRequest(req *ldap.ModifyRequest) error {
-	err := cl.ldap.Modify(req)
-	return err
+func (cl *Client) modifyRequest(ctx context.Context, req *ldap.ModifyRequest) error {
+	return cl.withConn(ctx, func(conn ldap.Client) error {
+		return doContext(ctx, func() error { return conn.Modify(req) })
+	})
+}
+
+// ModifyDN request
+func (cl *Client) modifyDNRequest(ctx context.Context, req *ldap.ModifyDNRequest) error {
+	return cl.withConn(ctx, func(conn ldap.Client) error {
+		return doContext(ctx, func() error { return conn.ModifyDN(req) })
+	})
 }
 
-func (cl *Client) modifyPassword(userDN string, pwd string) error {
+// Encodes password to unicodePwd attribute value.
+func encodePassword(pwd string) string {
 	utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
 	// According to the MS docs in the links above
 	// The password needs to be enclosed in quotes
 	quoted := fmt.Sprintf("\"%v\"", pwd)
 	pwdEncoded, _ := utf16.NewEncoder().String(quoted)
+	return pwdEncoded
+}
+
+func (cl *Client) modifyPassword(ctx context.Context, userDN string, pwd string) error {
+	passwordModify := ldap.NewModifyRequest(userDN, nil)
+	passwordModify.Replace("unicodePwd", []string{encodePassword(pwd)})
+
+	return passwordError(cl.modifyRequest(ctx, passwordModify))
+}
+
+// Changes password with delete of the old value and add of the new one in a single request,
+// so AD verifies the old password and applies password history and minimum age rules.
+func (cl *Client) changePassword(ctx context.Context, userDN string, oldPwd, newPwd string) error {
 	passwordModify := ldap.NewModifyRequest(userDN, nil)
-	passwordModify.Replace("unicodePwd", []string{pwdEncoded})
+	passwordModify.Delete("unicodePwd", []string{encodePassword(oldPwd)})
+	passwordModify.Add("unicodePwd", []string{encodePassword(newPwd)})
 
-	return cl.ldap.Modify(passwordModify)
+	return passwordError(cl.modifyRequest(ctx, passwordModify))
 }
 
 // SearchEntries Perfroms search for ldap entries.
-func (cl *Client) searchEntries(req *ldap.SearchRequest) ([]*ldap.Entry, error) {
-	result, err := cl.ldap.Search(req)
+func (cl *Client) searchEntries(ctx context.Context, req *ldap.SearchRequest) ([]*ldap.Entry, error) {
+	result, err := cl.search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -189,23 +638,58 @@ func (cl *Client) searchEntries(req *ldap.SearchRequest) ([]*ldap.Entry, error)
 }
 
 // Performs update for provided entry attribure by entry DN.
-func (cl *Client) updateAttribute(dn string, attribute string, values []string) error {
+func (cl *Client) updateAttribute(ctx context.Context, dn string, attribute string, values []string) error {
 	mr := ldap.NewModifyRequest(dn, nil)
 	mr.Replace(attribute, values)
-	return cl.ldap.Modify(mr)
+	return cl.modifyRequest(ctx, mr)
 }
 
 // Tries to authorise in AcitveDirecotry by provided DN and password and return error if failed.
 // Use this method to check if user can be authenticated in AD.
+// Config.AuthMechanism is used, so DN is expected as 'DOMAIN\user' or 'user@domain' for NTLM.
 func (cl *Client) CheckAuthByDN(dn, password string) error {
-	conn, err := cl.connect()
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
+	return cl.CheckAuthByDNContext(context.Background(), dn, password)
+}
 
-	if err := conn.Bind(dn, password); err != nil {
-		return err
+// Context-aware version of CheckAuthByDN.
+func (cl *Client) CheckAuthByDNContext(ctx context.Context, dn, password string) error {
+	return doContext(ctx, func() error {
+		conn, _, err := cl.connect(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		return bindConn(conn, &BindAccount{DN: dn, Mechanism: cl.Config.AuthMechanism}, password)
+	})
+}
+
+// Returns client that performs operations as provided user on separately bound connection, so AD permissions
+// of the user are enforced. Configuration, filters and attributes lists of the client are reused.
+// Config.AuthMechanism is used to bind. Call Disconnect on returned client when it's no longer needed.
+func (cl *Client) As(dn, password string) (*Client, error) {
+	return cl.AsContext(context.Background(), dn, password)
+}
+
+// Context-aware version of As.
+func (cl *Client) AsContext(ctx context.Context, dn, password string) (*Client, error) {
+	cfg := *cl.Config
+	cfg.Bind = &BindAccount{DN: dn, Password: password, Mechanism: cl.Config.AuthMechanism}
+	// Single connection is enough for user operations.
+	cfg.Pool = &PoolConfig{MinSize: 1, MaxSize: 1, IdleTimeout: cl.Config.Pool.IdleTimeout}
+
+	scoped := &Client{
+		Config:      &cfg,
+		servers:     newServerList(),
+		discovery:   cl.discovery,
+		credentials: &credentials{},
+		gssapi:      cl.gssapi,
+		tlsBase:     cl.tlsBase,
+		logger:      cl.logger,
+		mockMode:    cl.mockMode,
 	}
-	return nil
+	if err := scoped.ConnectContext(ctx); err != nil {
+		return nil, err
+	}
+	return scoped, nil
 }
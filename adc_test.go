@@ -2,9 +2,12 @@ package adc
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-ldap/ldap/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -72,7 +75,17 @@ func Test_Client_Disconnect(t *testing.T) {
 	t.Run("Ok", func(t *testing.T) {
 		cl := newMockClient(&Config{Bind: validMockBind})
 		require.NoError(t, cl.Connect())
+		require.True(t, cl.ConnectedStatus())
 		require.NoError(t, cl.Disconnect())
+		require.False(t, cl.ConnectedStatus())
+
+		_, err := cl.GetUser(GetUserArgs{Id: "user1"})
+		require.ErrorIs(t, err, ErrNotConnected)
+	})
+	t.Run("NotConnected", func(t *testing.T) {
+		cl := newMockClient(nil)
+		_, err := cl.GetUser(GetUserArgs{Id: "user1"})
+		require.ErrorIs(t, err, ErrNotConnected)
 	})
 }
 
@@ -110,8 +123,10 @@ func Test_Client_Reconnect(t *testing.T) {
 
 		require.Error(t, cl.Reconnect(ctx, 5*time.Second, 1))
 
+		require.ErrorIs(t, cl.Reconnect(ctx, 30*time.Millisecond, 1), context.Canceled)
+
 		cl.Config.Bind = validMockBind
-		require.NoError(t, cl.Reconnect(ctx, 30*time.Millisecond, 1))
+		require.NoError(t, cl.Reconnect(context.TODO(), 30*time.Millisecond, 1))
 	})
 	t.Run("Ok", func(t *testing.T) {
 		ctx := context.TODO()
@@ -121,6 +136,12 @@ func Test_Client_Reconnect(t *testing.T) {
 		require.NoError(t, cl.Connect())
 		require.NoError(t, cl.Reconnect(ctx, 2*time.Second, 2), "Reconnect should be successful")
 	})
+	t.Run("Bindless", func(t *testing.T) {
+		cl := newMockClient(nil)
+
+		require.NoError(t, cl.Connect())
+		require.NoError(t, cl.Reconnect(context.TODO(), 2*time.Second, 2), "Reconnect without bind account should be successful")
+	})
 }
 
 func Test_Client_CheckAuthByDN(t *testing.T) {
@@ -134,4 +155,192 @@ func Test_Client_CheckAuthByDN(t *testing.T) {
 		require.NoError(t, cl.Connect())
 		require.NoError(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
 	})
+	t.Run("WithContextCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.Connect())
+		require.ErrorIs(t, cl.CheckAuthByDNContext(ctx, validMockBind.DN, validMockBind.Password), context.Canceled)
+	})
+}
+
+func Test_Client_As(t *testing.T) {
+	boundDN := func(t *testing.T, cl *Client) string {
+		var dn string
+		require.NoError(t, cl.withConn(context.Background(), func(conn ldap.Client) error {
+			dn = conn.(*mockClient).boundDN
+			return nil
+		}))
+		return dn
+	}
+
+	cl := newMockClient(&Config{URL: "ldap://dc1:389", Bind: validMockBind, SearchBase: "DC=company,DC=com"})
+	require.NoError(t, cl.Connect())
+
+	t.Run("Ok", func(t *testing.T) {
+		scoped, err := cl.As(user1MockBind.DN, user1MockBind.Password)
+		require.NoError(t, err)
+		require.Equal(t, user1MockBind.DN, boundDN(t, scoped))
+		require.Equal(t, cl.Config.SearchBase, scoped.Config.SearchBase)
+		require.Equal(t, cl.Config.Users, scoped.Config.Users)
+
+		user, err := scoped.GetUser(GetUserArgs{Id: "user1"})
+		require.NoError(t, err)
+		require.NotNil(t, user)
+
+		// Parent client is unaffected.
+		require.Equal(t, validMockBind, cl.Config.Bind)
+		require.Equal(t, validMockBind.DN, boundDN(t, cl))
+		require.NoError(t, scoped.Disconnect())
+		require.True(t, cl.ConnectedStatus())
+	})
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		scoped, err := cl.As(user1MockBind.DN, "badPass")
+		require.Error(t, err)
+		require.Nil(t, scoped)
+	})
+	t.Run("WithContextCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := cl.AsContext(ctx, user1MockBind.DN, user1MockBind.Password)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test_Client_ConnectContext(t *testing.T) {
+	t.Run("WithContextCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.ErrorIs(t, cl.ConnectContext(ctx), context.Canceled)
+		require.False(t, cl.ConnectedStatus())
+	})
+	t.Run("Ok", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.NoError(t, cl.ConnectContext(context.Background()))
+		require.True(t, cl.ConnectedStatus())
+	})
+}
+
+func Test_doContext(t *testing.T) {
+	t.Run("Done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		err := doContext(ctx, func() error {
+			<-release
+			return nil
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Ok", func(t *testing.T) {
+		require.NoError(t, doContext(context.Background(), func() error { return nil }))
+	})
+}
+
+func Test_Client_ConnectedStatus(t *testing.T) {
+	t.Run("NotConnected", func(t *testing.T) {
+		cl := newMockClient(nil)
+		require.False(t, cl.ConnectedStatus())
+	})
+	t.Run("NetworkErr", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: &RetryConfig{Disabled: true}})
+		connectWithBrokenConns(t, cl, 1)
+		require.True(t, cl.ConnectedStatus())
+
+		_, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.Error(t, err)
+		require.False(t, cl.ConnectedStatus(), "Client should not be connected after network failure")
+		require.Equal(t, err, cl.LastError())
+
+		_, err = cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.NoError(t, err)
+		require.True(t, cl.ConnectedStatus())
+		require.NoError(t, cl.LastError())
+	})
+	t.Run("ConnectionClosing", func(t *testing.T) {
+		cl := newMockClient(nil)
+		require.NoError(t, cl.Connect())
+		for _, pc := range cl.getPool().idle {
+			pc.conn.Close()
+		}
+		require.False(t, cl.ConnectedStatus())
+	})
+}
+
+func Test_Client_getConn(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+	defer cl.Disconnect()
+
+	// Operation picked up the pool right before Connect replaced and closed it.
+	old := cl.getPool()
+	require.NoError(t, cl.Connect())
+	require.NoError(t, old.close(context.Background()))
+
+	conn, p, err := cl.getConn(context.Background(), old)
+	require.NoError(t, err)
+	require.Equal(t, cl.getPool(), p)
+	p.put(conn, nil)
+
+	// Closed pool that wasn't replaced isn't retried.
+	current := cl.getPool()
+	require.NoError(t, cl.Disconnect())
+	_, _, err = cl.getConn(context.Background(), current)
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
+
+func Test_Client_Concurrency(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind, Pool: &PoolConfig{MaxSize: 4}})
+	require.NoError(t, cl.Connect())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Workers report failures to the test goroutine, as require can't stop the test from other goroutines.
+	workers := 8
+	errCh := make(chan error, workers)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				user, err := cl.GetUserContext(ctx, GetUserArgs{Id: "user1"})
+				if err != nil {
+					// Only errors caused by the test end are expected.
+					if ctx.Err() == nil {
+						errCh <- err
+						return
+					}
+					continue
+				}
+				if user == nil || user.Id != "user1" {
+					errCh <- fmt.Errorf("unexpected user %+v", user)
+					return
+				}
+				cl.ConnectedStatus()
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		require.NoError(t, cl.Connect())
+		require.NoError(t, cl.Reconnect(context.Background(), time.Millisecond, 1))
+	}
+	cancel()
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, cl.Disconnect())
+	require.False(t, cl.ConnectedStatus())
+	require.NoError(t, cl.Disconnect(), "Repeated disconnect should be no-op")
 }
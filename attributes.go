@@ -0,0 +1,286 @@
+package adc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Binary attributes that are decoded to their string forms.
+var binaryAttributeDecoders = map[string]func([]byte) (string, error){
+	"objectguid": DecodeGUID,
+	"objectsid":  DecodeSID,
+}
+
+// Converts entry attributes to map of first attribute values.
+// Binary GUID and SID attributes are decoded to their string forms.
+func entryAttributes(entry *ldap.Entry) map[string]interface{} {
+	result := make(map[string]interface{}, len(entry.Attributes))
+	for _, a := range entry.Attributes {
+		result[a.Name] = entry.GetAttributeValue(a.Name)
+		decode, ok := binaryAttributeDecoders[strings.ToLower(a.Name)]
+		if !ok {
+			continue
+		}
+		if s, err := decode(rawAttributeValue(a)); err == nil {
+			result[a.Name] = s
+		}
+	}
+	return result
+}
+
+func rawAttributeValue(a *ldap.EntryAttribute) []byte {
+	if len(a.ByteValues) > 0 {
+		return a.ByteValues[0]
+	}
+	if len(a.Values) > 0 {
+		return []byte(a.Values[0])
+	}
+	return nil
+}
+
+// Decodes binary objectGUID to canonical form 'xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx'.
+// First three GUID components are stored in little-endian byte order.
+func DecodeGUID(b []byte) (string, error) {
+	if len(b) != 16 {
+		return "", fmt.Errorf("invalid GUID length %d", len(b))
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%s-%s",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		hex.EncodeToString(b[8:10]),
+		hex.EncodeToString(b[10:16]),
+	), nil
+}
+
+// Decodes binary objectSid to string form 'S-1-5-21-...'.
+func DecodeSID(b []byte) (string, error) {
+	if len(b) < 8 {
+		return "", fmt.Errorf("invalid SID length %d", len(b))
+	}
+	count := int(b[1])
+	if len(b) != 8+4*count {
+		return "", fmt.Errorf("invalid SID length %d for %d sub-authorities", len(b), count)
+	}
+
+	// Identifier authority is 48-bit big-endian value.
+	var authority uint64
+	for _, v := range b[2:8] {
+		authority = authority<<8 | uint64(v)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "S-%d-%d", b[0], authority)
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&sb, "-%d", binary.LittleEndian.Uint32(b[8+4*i:]))
+	}
+	return sb.String(), nil
+}
+
+// Encodes GUID in canonical form 'xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx', optionally in braces, to binary objectGUID.
+func EncodeGUID(guid string) ([]byte, error) {
+	s := strings.TrimSuffix(strings.TrimPrefix(guid, "{"), "}")
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return nil, fmt.Errorf("invalid GUID '%s'", guid)
+	}
+	b, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GUID '%s': %w", guid, err)
+	}
+	// First three components are stored in little-endian byte order.
+	slices.Reverse(b[0:4])
+	slices.Reverse(b[4:6])
+	slices.Reverse(b[6:8])
+	return b, nil
+}
+
+// Encodes SID in 'S-1-5-...' form to binary objectSid.
+func EncodeSID(sid string) ([]byte, error) {
+	parts := strings.Split(sid, "-")
+	if len(parts) < 3 || !strings.EqualFold(parts[0], "S") || len(parts)-3 > 15 {
+		return nil, fmt.Errorf("invalid SID '%s'", sid)
+	}
+	revision, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SID '%s' revision: %w", sid, err)
+	}
+	authority, err := strconv.ParseUint(parts[2], 10, 48)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SID '%s' authority: %w", sid, err)
+	}
+
+	b := make([]byte, 8, 8+4*(len(parts)-3))
+	b[0] = byte(revision)
+	b[1] = byte(len(parts) - 3)
+	for i := 7; i >= 2; i-- {
+		b[i] = byte(authority)
+		authority >>= 8
+	}
+	for _, p := range parts[3:] {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SID '%s' sub-authority: %w", sid, err)
+		}
+		b = binary.LittleEndian.AppendUint32(b, uint32(v))
+	}
+	return b, nil
+}
+
+// Escapes every byte of binary value as '\xx' to use it in LDAP filter.
+func escapeFilterBytes(b []byte) string {
+	var sb strings.Builder
+	for _, v := range b {
+		fmt.Fprintf(&sb, "\\%02x", v)
+	}
+	return sb.String()
+}
+
+const (
+	// FILETIME value that means the time is never reached, e.g. account never expires.
+	fileTimeNever = 0x7FFFFFFFFFFFFFFF
+	// Number of 100-nanosecond intervals between January 1, 1601 and January 1, 1970.
+	fileTimeUnixEpoch = 116444736000000000
+)
+
+// Converts Windows FILETIME, number of 100-nanosecond intervals since January 1, 1601 UTC, to time.
+// Returns zero time for 0 and maximum values that mean never.
+func FileTimeToTime(ft int64) time.Time {
+	if ft <= 0 || ft == fileTimeNever {
+		return time.Time{}
+	}
+	ft -= fileTimeUnixEpoch
+	return time.Unix(ft/1e7, ft%1e7*100).UTC()
+}
+
+// Converts time to Windows FILETIME. Zero time is converted to 0.
+func TimeToFileTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()*1e7 + int64(t.Nanosecond())/100 + fileTimeUnixEpoch
+}
+
+// Parses AD time attribute value in FILETIME or GeneralizedTime format.
+// Returns zero time if value can't be parsed or means never.
+func ParseTime(value string) time.Time {
+	if ft, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return FileTimeToTime(ft)
+	}
+	// Fractional seconds are accepted by the parser even if layout doesn't include them.
+	for _, layout := range []string{"20060102150405Z0700", "20060102150405Z"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// Parses AD interval attribute value, negative number of 100-nanosecond intervals, e.g. lockoutDuration.
+// Returns zero duration for minimal value that means forever.
+func ParseInterval(value string) time.Duration {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || v == math.MinInt64 {
+		return 0
+	}
+	if v < 0 {
+		v = -v
+	}
+	return time.Duration(v) * 100
+}
+
+// Flags of userAccountControl attribute.
+type UACFlags uint32
+
+const (
+	UACScript                       UACFlags = 0x0001
+	UACAccountDisable               UACFlags = 0x0002
+	UACHomedirRequired              UACFlags = 0x0008
+	UACLockout                      UACFlags = 0x0010
+	UACPasswordNotRequired          UACFlags = 0x0020
+	UACPasswordCantChange           UACFlags = 0x0040
+	UACEncryptedTextPasswordAllowed UACFlags = 0x0080
+	UACTempDuplicateAccount         UACFlags = 0x0100
+	UACNormalAccount                UACFlags = 0x0200
+	UACInterdomainTrustAccount      UACFlags = 0x0800
+	UACWorkstationTrustAccount      UACFlags = 0x1000
+	UACServerTrustAccount           UACFlags = 0x2000
+	UACDontExpirePassword           UACFlags = 0x10000
+	UACMNSLogonAccount              UACFlags = 0x20000
+	UACSmartcardRequired            UACFlags = 0x40000
+	UACTrustedForDelegation         UACFlags = 0x80000
+	UACNotDelegated                 UACFlags = 0x100000
+	UACUseDESKeyOnly                UACFlags = 0x200000
+	UACDontRequirePreauth           UACFlags = 0x400000
+	UACPasswordExpired              UACFlags = 0x800000
+	UACTrustedToAuthForDelegation   UACFlags = 0x1000000
+	UACPartialSecretsAccount        UACFlags = 0x4000000
+)
+
+var uacFlagNames = []struct {
+	flag UACFlags
+	name string
+}{
+	{UACScript, "SCRIPT"},
+	{UACAccountDisable, "ACCOUNTDISABLE"},
+	{UACHomedirRequired, "HOMEDIR_REQUIRED"},
+	{UACLockout, "LOCKOUT"},
+	{UACPasswordNotRequired, "PASSWD_NOTREQD"},
+	{UACPasswordCantChange, "PASSWD_CANT_CHANGE"},
+	{UACEncryptedTextPasswordAllowed, "ENCRYPTED_TEXT_PWD_ALLOWED"},
+	{UACTempDuplicateAccount, "TEMP_DUPLICATE_ACCOUNT"},
+	{UACNormalAccount, "NORMAL_ACCOUNT"},
+	{UACInterdomainTrustAccount, "INTERDOMAIN_TRUST_ACCOUNT"},
+	{UACWorkstationTrustAccount, "WORKSTATION_TRUST_ACCOUNT"},
+	{UACServerTrustAccount, "SERVER_TRUST_ACCOUNT"},
+	{UACDontExpirePassword, "DONT_EXPIRE_PASSWORD"},
+	{UACMNSLogonAccount, "MNS_LOGON_ACCOUNT"},
+	{UACSmartcardRequired, "SMARTCARD_REQUIRED"},
+	{UACTrustedForDelegation, "TRUSTED_FOR_DELEGATION"},
+	{UACNotDelegated, "NOT_DELEGATED"},
+	{UACUseDESKeyOnly, "USE_DES_KEY_ONLY"},
+	{UACDontRequirePreauth, "DONT_REQ_PREAUTH"},
+	{UACPasswordExpired, "PASSWORD_EXPIRED"},
+	{UACTrustedToAuthForDelegation, "TRUSTED_TO_AUTH_FOR_DELEGATION"},
+	{UACPartialSecretsAccount, "PARTIAL_SECRETS_ACCOUNT"},
+}
+
+// Reports whether all provided flags are set.
+func (f UACFlags) Has(flags UACFlags) bool {
+	return f&flags == flags
+}
+
+// Returns names of set flags separated by '|'.
+func (f UACFlags) String() string {
+	var names []string
+	for _, n := range uacFlagNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+			f &^= n.flag
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(f)))
+	}
+	return strings.Join(names, "|")
+}
+
+func stringAttribute(attrs map[string]interface{}, name string) string {
+	if s, ok := attrs[name].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func intAttribute(attrs map[string]interface{}, name string) int64 {
+	v, _ := strconv.ParseInt(stringAttribute(attrs, name), 10, 64)
+	return v
+}
@@ -0,0 +1,130 @@
+package adc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DecodeGUID(t *testing.T) {
+	guid, err := DecodeGUID(mockUser1GUID)
+	require.NoError(t, err)
+	require.Equal(t, "04030201-0605-0807-090a-0b0c0d0e0f10", guid)
+
+	_, err = DecodeGUID([]byte{1, 2, 3})
+	require.Error(t, err)
+}
+
+func Test_DecodeSID(t *testing.T) {
+	sid, err := DecodeSID(mockSID(mockDomainSubAuthorities, 512))
+	require.NoError(t, err)
+	require.Equal(t, "S-1-5-21-1004336348-1177238915-682003330-512", sid)
+
+	sid, err = DecodeSID([]byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 32, 2, 0, 0})
+	require.NoError(t, err)
+	require.Equal(t, "S-1-5-32-544", sid, "Well-known BUILTIN\\Administrators SID")
+
+	_, err = DecodeSID([]byte{1, 2, 0, 0})
+	require.Error(t, err)
+	_, err = DecodeSID([]byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0})
+	require.Error(t, err)
+}
+
+func Test_FileTime(t *testing.T) {
+	date := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, date, FileTimeToTime(132539328000000000))
+	require.Equal(t, int64(132539328000000000), TimeToFileTime(date))
+
+	precise := time.Date(2024, 2, 29, 13, 14, 15, 123456700, time.UTC)
+	require.Equal(t, precise, FileTimeToTime(TimeToFileTime(precise)))
+
+	require.True(t, FileTimeToTime(0).IsZero())
+	require.True(t, FileTimeToTime(fileTimeNever).IsZero())
+	require.Equal(t, int64(0), TimeToFileTime(time.Time{}))
+}
+
+func Test_ParseTime(t *testing.T) {
+	require.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ParseTime("132539328000000000"))
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), ParseTime("20240115103000.0Z"))
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), ParseTime("20240115123000.0+0200"))
+	require.True(t, ParseTime("0").IsZero())
+	require.True(t, ParseTime("9223372036854775807").IsZero())
+	require.True(t, ParseTime("").IsZero())
+	require.True(t, ParseTime("yesterday").IsZero())
+}
+
+func Test_UACFlags(t *testing.T) {
+	flags := UACFlags(66050)
+	require.True(t, flags.Has(UACAccountDisable))
+	require.True(t, flags.Has(UACNormalAccount|UACDontExpirePassword))
+	require.False(t, flags.Has(UACLockout))
+	require.False(t, flags.Has(UACAccountDisable|UACLockout))
+	require.Equal(t, "ACCOUNTDISABLE|NORMAL_ACCOUNT|DONT_EXPIRE_PASSWORD", flags.String())
+	require.Equal(t, "NORMAL_ACCOUNT|0x40000000", UACFlags(0x40000200).String())
+	require.Equal(t, "", UACFlags(0).String())
+}
+
+func Test_User_typedAttributes(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	user, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+	require.NoError(t, err)
+	require.NotNil(t, user)
+
+	require.Equal(t, "04030201-0605-0807-090a-0b0c0d0e0f10", user.GUID())
+	require.Equal(t, "S-1-5-21-1004336348-1177238915-682003330-1105", user.SID())
+	require.Equal(t, user.GUID(), user.GetStringAttribute("objectGUID"))
+	require.Equal(t, UACNormalAccount|UACDontExpirePassword, user.UserAccountControl())
+	require.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), user.PasswordLastSet())
+	require.True(t, user.AccountExpires().IsZero(), "Account never expires")
+	require.True(t, user.LastLogon().IsZero(), "Attribute isn't set")
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), user.WhenCreated())
+
+	group := &Group{Attributes: map[string]interface{}{
+		"objectGUID":  "04030201-0605-0807-090a-0b0c0d0e0f10",
+		"whenChanged": "20240115103000.0Z",
+	}}
+	require.Equal(t, "04030201-0605-0807-090a-0b0c0d0e0f10", group.GUID())
+	require.Empty(t, group.SID())
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), group.WhenChanged())
+}
+
+func Test_EncodeGUID(t *testing.T) {
+	b, err := EncodeGUID("04030201-0605-0807-090a-0b0c0d0e0f10")
+	require.NoError(t, err)
+	require.Equal(t, mockUser1GUID, b)
+
+	b, err = EncodeGUID("{04030201-0605-0807-090A-0B0C0D0E0F10}")
+	require.NoError(t, err)
+	require.Equal(t, mockUser1GUID, b)
+
+	for _, guid := range []string{"", "04030201060508070", "0403020-10605-0807-090a-0b0c0d0e0f10", "zz030201-0605-0807-090a-0b0c0d0e0f10"} {
+		_, err := EncodeGUID(guid)
+		require.Error(t, err, guid)
+	}
+}
+
+func Test_EncodeSID(t *testing.T) {
+	sid := mockSID(mockDomainSubAuthorities, 1105)
+	b, err := EncodeSID("S-1-5-21-1004336348-1177238915-682003330-1105")
+	require.NoError(t, err)
+	require.Equal(t, sid, b)
+
+	b, err = EncodeSID("S-1-5-32")
+	require.NoError(t, err)
+	s, err := DecodeSID(b)
+	require.NoError(t, err)
+	require.Equal(t, "S-1-5-32", s)
+
+	for _, s := range []string{"", "S-1", "X-1-5-21", "S-1-5-x", "S-1-5-4294967296", "S-1-281474976710656-1"} {
+		_, err := EncodeSID(s)
+		require.Error(t, err, s)
+	}
+}
+
+func Test_escapeFilterBytes(t *testing.T) {
+	require.Equal(t, `\01\0a\ff`, escapeFilterBytes([]byte{1, 10, 255}))
+	require.Empty(t, escapeFilterBytes(nil))
+}
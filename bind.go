@@ -0,0 +1,143 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Mechanism to authenticate in AD.
+type BindMechanism string
+
+const (
+	// Simple bind with DN and password. Default mechanism.
+	BindSimple BindMechanism = "simple"
+	// NTLM bind with account name as 'DOMAIN\user' or 'user@domain' and password or NT hash.
+	BindNTLM BindMechanism = "ntlm"
+	// SASL EXTERNAL bind with TLS client certificate. Requires TLS connection.
+	BindExternal BindMechanism = "external"
+	// SASL GSSAPI (Kerberos) bind with client provided by WithGSSAPIClient option.
+	BindGSSAPI BindMechanism = "gssapi"
+)
+
+var (
+	// Returned when SASL EXTERNAL bind is requested over connection without TLS.
+	ErrExternalBindRequiresTLS = errors.New("SASL EXTERNAL bind requires TLS connection")
+	// Returned when GSSAPI bind is requested, but GSSAPI client isn't provided.
+	ErrNoGSSAPIClient = errors.New("GSSAPI client isn't provided")
+)
+
+// Creates GSSAPI client for a new connection, e.g. client that authenticates with keytab.
+// Client must select security layer in NegotiateSaslAuth. LDAP messages aren't signed or sealed
+// by GSSAPI after bind, so client should select no security layer and rely on TLS for protection.
+type GSSAPIClientFactory func() (ldap.GSSAPIClient, error)
+
+// Specifies GSSAPI client factory for GSSAPI bind.
+func WithGSSAPIClient(f GSSAPIClientFactory) Option {
+	return func(cl *Client) { cl.gssapi = f }
+}
+
+// Connection that supports GSSAPI bind. Implemented by *ldap.Conn.
+type gssapiBinder interface {
+	GSSAPIBindRequest(client ldap.GSSAPIClient, req *ldap.GSSAPIBindRequest) error
+}
+
+// Connection that supports NTLM bind. Implemented by *ldap.Conn.
+type ntlmBinder interface {
+	NTLMBind(domain, username, password string) error
+	NTLMBindWithHash(domain, username, hash string) error
+}
+
+func (b *BindAccount) mechanism() BindMechanism {
+	if b.Mechanism == "" {
+		return BindSimple
+	}
+	return b.Mechanism
+}
+
+// Splits NTLM account name to domain and user name.
+func ntlmAccount(name string) (domain, username string) {
+	if domain, username, ok := strings.Cut(name, `\`); ok {
+		return domain, username
+	}
+	return "", name
+}
+
+// Binds connection with provided account and password according to account bind mechanism.
+func bindConn(conn ldap.Client, account *BindAccount, password string) error {
+	switch account.mechanism() {
+	case BindSimple:
+		return conn.Bind(account.DN, password)
+	case BindNTLM:
+		binder, ok := conn.(ntlmBinder)
+		if !ok {
+			return errors.New("connection doesn't support NTLM bind")
+		}
+		domain, username := ntlmAccount(account.DN)
+		if account.NTHash != "" {
+			return binder.NTLMBindWithHash(domain, username, account.NTHash)
+		}
+		return binder.NTLMBind(domain, username, password)
+	case BindExternal:
+		if _, ok := conn.TLSConnectionState(); !ok {
+			return ErrExternalBindRequiresTLS
+		}
+		return conn.ExternalBind()
+	}
+	return fmt.Errorf("unsupported bind mechanism '%s'", account.Mechanism)
+}
+
+// Binds connection with bind account. Bind is retried once with password fetched again
+// if it fails with invalid credentials, since password might have been rotated.
+func (cl *Client) bind(ctx context.Context, conn ldap.Client, serverURL string) error {
+	account := cl.Config.Bind
+	if account.mechanism() == BindGSSAPI {
+		return cl.gssapiBind(conn, account, serverURL)
+	}
+
+	password, err := cl.credentials.get(ctx, account, false)
+	if err != nil {
+		return err
+	}
+	err = bindConn(conn, account, password)
+	if account.Credentials == nil || !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return err
+	}
+
+	rotated, fetchErr := cl.credentials.get(ctx, account, true)
+	if fetchErr != nil {
+		return errors.Join(err, fetchErr)
+	}
+	if rotated == password {
+		return err
+	}
+	cl.logger.Debug("Bind credentials were rotated, rebinding")
+	return bindConn(conn, account, rotated)
+}
+
+// Binds connection with GSSAPI. Service principal defaults to 'ldap/<server host>'.
+func (cl *Client) gssapiBind(conn ldap.Client, account *BindAccount, serverURL string) error {
+	if cl.gssapi == nil {
+		return ErrNoGSSAPIClient
+	}
+	binder, ok := conn.(gssapiBinder)
+	if !ok {
+		return errors.New("connection doesn't support GSSAPI bind")
+	}
+
+	spn := account.ServicePrincipal
+	if spn == "" {
+		spn = "ldap/" + urlHostname(serverURL)
+	}
+	client, err := cl.gssapi()
+	if err != nil {
+		return fmt.Errorf("failed to create GSSAPI client: %w", err)
+	}
+	return binder.GSSAPIBindRequest(client, &ldap.GSSAPIBindRequest{
+		ServicePrincipalName: spn,
+		AuthZID:              account.AuthzID,
+	})
+}
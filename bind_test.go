@@ -0,0 +1,145 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// Fake GSSAPI client that issues provided ticket for any service principal.
+type fakeGSSAPIClient struct {
+	ticket  string
+	target  string
+	layer   byte
+	deleted atomic.Bool
+}
+
+func (c *fakeGSSAPIClient) InitSecContext(target string, token []byte) ([]byte, bool, error) {
+	c.target = target
+	return []byte(c.ticket), false, nil
+}
+
+func (c *fakeGSSAPIClient) NegotiateSaslAuth(token []byte, authzid string) ([]byte, error) {
+	if len(token) != 4 {
+		return nil, errors.New("bad security layers token")
+	}
+	c.layer = token[0]
+	return []byte{0x01, 0, 0, 0}, nil
+}
+
+func (c *fakeGSSAPIClient) DeleteSecContext() error {
+	c.deleted.Store(true)
+	return nil
+}
+
+func Test_ntlmAccount(t *testing.T) {
+	domain, username := ntlmAccount(`COMPANY\user`)
+	require.Equal(t, "COMPANY", domain)
+	require.Equal(t, "user", username)
+
+	domain, username = ntlmAccount("user@company.com")
+	require.Equal(t, "", domain)
+	require.Equal(t, "user@company.com", username)
+}
+
+func Test_bindConn(t *testing.T) {
+	conn, err := mockConnection()
+	require.NoError(t, err)
+	ntlmDN := mockNTLMDomain + `\` + validMockBind.DN
+
+	t.Run("Simple", func(t *testing.T) {
+		require.NoError(t, bindConn(conn, &BindAccount{DN: validMockBind.DN}, validMockBind.Password))
+		require.NoError(t, bindConn(conn, &BindAccount{DN: validMockBind.DN, Mechanism: BindSimple}, validMockBind.Password))
+		err := bindConn(conn, &BindAccount{DN: validMockBind.DN}, "badPass")
+		require.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials))
+	})
+	t.Run("NTLM", func(t *testing.T) {
+		require.NoError(t, bindConn(conn, &BindAccount{DN: ntlmDN, Mechanism: BindNTLM}, validMockBind.Password))
+		require.Error(t, bindConn(conn, &BindAccount{DN: ntlmDN, Mechanism: BindNTLM}, "badPass"))
+		require.Error(t, bindConn(conn, &BindAccount{DN: validMockBind.DN, Mechanism: BindNTLM}, validMockBind.Password))
+	})
+	t.Run("NTLMWithHash", func(t *testing.T) {
+		require.NoError(t, bindConn(conn, &BindAccount{DN: ntlmDN, Mechanism: BindNTLM, NTHash: mockNTHash}, ""))
+		require.Error(t, bindConn(conn, &BindAccount{DN: ntlmDN, Mechanism: BindNTLM, NTHash: "00"}, validMockBind.Password))
+	})
+	t.Run("NTLMNotSupported", func(t *testing.T) {
+		wrapped := struct{ ldap.Client }{conn}
+		require.Error(t, bindConn(wrapped, &BindAccount{DN: ntlmDN, Mechanism: BindNTLM}, validMockBind.Password))
+	})
+	t.Run("External", func(t *testing.T) {
+		require.ErrorIs(t, bindConn(conn, &BindAccount{Mechanism: BindExternal}, ""), ErrExternalBindRequiresTLS)
+
+		tlsConn, err := mockDial("ldaps://dc:636")
+		require.NoError(t, err)
+		require.NoError(t, bindConn(tlsConn, &BindAccount{Mechanism: BindExternal}, ""))
+	})
+	t.Run("Unsupported", func(t *testing.T) {
+		require.ErrorContains(t, bindConn(conn, &BindAccount{Mechanism: "kerberos"}, ""), "unsupported bind mechanism")
+	})
+}
+
+func Test_Client_Connect_BindMechanism(t *testing.T) {
+	t.Run("NTLM", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: &BindAccount{
+			DN:        mockNTLMDomain + `\` + validMockBind.DN,
+			Password:  validMockBind.Password,
+			Mechanism: BindNTLM,
+		}})
+		require.NoError(t, cl.Connect())
+		require.True(t, cl.ConnectedStatus())
+		require.NoError(t, cl.probe(context.Background()))
+	})
+	t.Run("External", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldaps://dc:636", Bind: &BindAccount{Mechanism: BindExternal}})
+		require.NoError(t, cl.Connect())
+
+		cl = newMockClient(&Config{URL: "ldap://dc:389", Bind: &BindAccount{Mechanism: BindExternal}})
+		require.ErrorIs(t, cl.Connect(), ErrExternalBindRequiresTLS)
+	})
+}
+
+func Test_Client_Connect_GSSAPI(t *testing.T) {
+	cfg := &Config{URL: "ldap://dc1.company.com:389", Bind: &BindAccount{Mechanism: BindGSSAPI}}
+
+	t.Run("Success", func(t *testing.T) {
+		fake := &fakeGSSAPIClient{ticket: mockGSSAPITicket}
+		cl := newMockClient(cfg, WithGSSAPIClient(func() (ldap.GSSAPIClient, error) { return fake, nil }))
+		require.NoError(t, cl.Connect())
+		require.Equal(t, "ldap/dc1.company.com", fake.target)
+		require.Equal(t, byte(0x01), fake.layer)
+		require.True(t, fake.deleted.Load(), "Security context should be deleted")
+	})
+	t.Run("InvalidTicket", func(t *testing.T) {
+		fake := &fakeGSSAPIClient{ticket: "badTicket"}
+		cl := newMockClient(cfg, WithGSSAPIClient(func() (ldap.GSSAPIClient, error) { return fake, nil }))
+		require.Error(t, cl.Connect())
+		require.True(t, fake.deleted.Load())
+	})
+	t.Run("ServicePrincipal", func(t *testing.T) {
+		fake := &fakeGSSAPIClient{ticket: mockGSSAPITicket}
+		cl := newMockClient(
+			&Config{URL: cfg.URL, Bind: &BindAccount{Mechanism: BindGSSAPI, ServicePrincipal: "ldap/other.company.com"}},
+			WithGSSAPIClient(func() (ldap.GSSAPIClient, error) { return fake, nil }),
+		)
+		require.Error(t, cl.Connect())
+		require.Equal(t, "ldap/other.company.com", fake.target)
+	})
+	t.Run("NoClient", func(t *testing.T) {
+		cl := newMockClient(cfg)
+		require.ErrorIs(t, cl.Connect(), ErrNoGSSAPIClient)
+	})
+	t.Run("FactoryErr", func(t *testing.T) {
+		cl := newMockClient(cfg, WithGSSAPIClient(func() (ldap.GSSAPIClient, error) { return nil, errors.New("keytab not found") }))
+		require.ErrorContains(t, cl.Connect(), "keytab not found")
+	})
+}
+
+func Test_Client_CheckAuthByDN_NTLM(t *testing.T) {
+	cl := newMockClient(&Config{AuthMechanism: BindNTLM})
+	require.NoError(t, cl.CheckAuthByDN(mockNTLMDomain+`\`+validMockBind.DN, validMockBind.Password))
+	require.Error(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+}
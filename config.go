@@ -1,14 +1,35 @@
 package adc
 
 import (
+	"slices"
 	"time"
 )
 
 type Config struct {
 	// LDAP server URL. Examle 'ldaps://cl.local:636'
 	URL string `json:"url"`
+	// LDAP servers URLs for failover. URL is tried first if both provided.
+	URLs []string `json:"urls"`
+	// Strategy to select server from URLs: 'failover', 'random' or 'round-robin'. Default is 'failover'.
+	ServerSelection ServerSelection `json:"server_selection"`
+	// Time limit to establish connection with a single server.
+	DialTimeout time.Duration `json:"dial_timeout"`
+	// Period during which failed server is tried only after other servers.
+	ServerCooldown time.Duration `json:"server_cooldown"`
+	// AD domain name to discover domain controllers via DNS SRV records. Discovered servers are tried after URLs.
+	Domain string `json:"domain"`
+	// Optional AD site name. Domain controllers from the site are preferred during discovery.
+	Site string `json:"site"`
+	// Discover global catalog servers instead of domain controllers.
+	GlobalCatalog bool `json:"global_catalog"`
+	// Interval to refresh discovered servers. Default is 5 minutes.
+	DiscoveryRefresh time.Duration `json:"discovery_refresh"`
 	// Use insecure SSL connection.
 	InsecureTLS bool `json:"insecure_tls"`
+	// TLS mode: 'none', 'ldaps', 'starttls' or 'starttls-required'. Sets by URL scheme if not provided.
+	TLSMode TLSMode `json:"tls_mode"`
+	// TLS settings: CA bundle, client certificate, server name and minimum version.
+	TLS *TLSConfig `json:"tls"`
 	// Time limit for requests.
 	Timeout time.Duration
 	// Base OU for search requests.
@@ -16,6 +37,13 @@ type Config struct {
 
 	// Bind account info.
 	Bind *BindAccount `json:"bind"`
+	// Mechanism to check users credentials with CheckAuthByDN: 'simple' or 'ntlm'. Default is 'simple'.
+	AuthMechanism BindMechanism `json:"auth_mechanism"`
+
+	// Connections pool settings.
+	Pool *PoolConfig `json:"pool"`
+	// Reconnect and retry policy for network failures.
+	Retry *RetryConfig `json:"retry"`
 
 	// Requests filters vars.
 	Users *UsersConfigs `json:"users"`
@@ -24,9 +52,31 @@ type Config struct {
 }
 
 // Account attributes to authentificate in AD.
+// Password and NT hash are redacted from JSON and log output.
 type BindAccount struct {
+	// Account DN. Account name as 'DOMAIN\user' or 'user@domain' for NTLM bind.
 	DN       string `json:"dn"`
 	Password string `json:"password"`
+	// Optional password provider. Consulted on every connect instead of static password.
+	Credentials CredentialProvider `json:"-"`
+	// Bind mechanism: 'simple', 'ntlm', 'external' or 'gssapi'. Default is 'simple'.
+	Mechanism BindMechanism `json:"mechanism"`
+	// Hex encoded NT hash for NTLM bind instead of password.
+	NTHash string `json:"nt_hash"`
+	// Service principal name for GSSAPI bind. Default is 'ldap/<server host>'.
+	ServicePrincipal string `json:"service_principal"`
+	// Optional authorization identity for GSSAPI bind.
+	AuthzID string `json:"authz_id"`
+}
+
+// Settings of bound connections pool.
+type PoolConfig struct {
+	// Number of connections opened on connect and kept open while idle. Minimum and default is 1.
+	MinSize int `json:"min_size"`
+	// Maximum number of simultaneously opened connections. Default is 10.
+	MaxSize int `json:"max_size"`
+	// Idle connections above MinSize are closed after this timeout. Default is 5 minutes.
+	IdleTimeout time.Duration `json:"idle_timeout"`
 }
 
 type UsersConfigs struct {
@@ -42,6 +92,8 @@ type UsersConfigs struct {
 	FilterByDn string `json:"filter_by_dn"`
 	// LDAP filter to get user groups membership.
 	FilterGroupsByDn string `json:"filter_groups_by_dn"`
+	// LDAP filter to get user direct and nested groups membership.
+	FilterNestedGroupsByDn string `json:"filter_nested_groups_by_dn"`
 	// Filter by person
 	FilterByPerson string `json:"filter_by_person"`
 }
@@ -73,16 +125,42 @@ func (cfg *Config) AppendGroupsAttributes(attrs ...string) {
 	cfg.Groups.Attributes = append(cfg.Groups.Attributes, attrs...)
 }
 
+// Returns list of server URLs to connect to.
+func (cfg *Config) serverURLs() []string {
+	if len(cfg.URLs) == 0 {
+		return []string{cfg.URL}
+	}
+	if cfg.URL == "" || slices.Contains(cfg.URLs, cfg.URL) {
+		return cfg.URLs
+	}
+	return append([]string{cfg.URL}, cfg.URLs...)
+}
+
 func getDefaultConfig() *Config {
 	return &Config{
-		Timeout: 10 * time.Second,
+		Timeout:          10 * time.Second,
+		ServerSelection:  ServerSelectionFailover,
+		DialTimeout:      10 * time.Second,
+		ServerCooldown:   time.Minute,
+		DiscoveryRefresh: 5 * time.Minute,
+		Pool: &PoolConfig{
+			MinSize:     1,
+			MaxSize:     10,
+			IdleTimeout: 5 * time.Minute,
+		},
+		Retry: &RetryConfig{
+			MaxRetries:     3,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+		},
 		Users: &UsersConfigs{
-			IdAttribute:      "sAMAccountName",
-			Attributes:       []string{"sAMAccountName", "givenName", "sn", "mail"},
-			FilterById:       "(&(objectClass=person)(sAMAccountName=%v))",
-			FilterByDn:       "(&(objectClass=person)(distinguishedName=%v))",
-			FilterByPerson:   "(&(objectClass=person))",
-			FilterGroupsByDn: "(&(objectClass=group)(member=%v))",
+			IdAttribute:            "sAMAccountName",
+			Attributes:             []string{"sAMAccountName", "givenName", "sn", "mail"},
+			FilterById:             "(&(objectClass=person)(sAMAccountName=%v))",
+			FilterByDn:             "(&(objectClass=person)(distinguishedName=%v))",
+			FilterByPerson:         "(&(objectClass=person))",
+			FilterGroupsByDn:       "(&(objectClass=group)(member=%v))",
+			FilterNestedGroupsByDn: "(&(objectClass=group)(member:1.2.840.113556.1.4.1941:=%v))",
 		},
 		Groups: &GroupsConfigs{
 			IdAttribute:       "sAMAccountName",
@@ -103,13 +181,60 @@ func populateConfig(cfg *Config) *Config {
 	}
 
 	result.URL = cfg.URL
+	result.URLs = cfg.URLs
+	result.Domain = cfg.Domain
+	result.Site = cfg.Site
+	result.GlobalCatalog = cfg.GlobalCatalog
 	result.InsecureTLS = cfg.InsecureTLS
+	result.TLSMode = cfg.TLSMode
+	result.TLS = cfg.TLS
 	result.SearchBase = cfg.SearchBase
 	result.Bind = cfg.Bind
+	result.AuthMechanism = cfg.AuthMechanism
 
 	if cfg.Timeout != 0 {
 		result.Timeout = cfg.Timeout
 	}
+	if cfg.ServerSelection != "" {
+		result.ServerSelection = cfg.ServerSelection
+	}
+	if cfg.DialTimeout != 0 {
+		result.DialTimeout = cfg.DialTimeout
+	}
+	if cfg.ServerCooldown != 0 {
+		result.ServerCooldown = cfg.ServerCooldown
+	}
+	if cfg.DiscoveryRefresh != 0 {
+		result.DiscoveryRefresh = cfg.DiscoveryRefresh
+	}
+
+	if cfg.Pool != nil {
+		if cfg.Pool.MinSize > 0 {
+			result.Pool.MinSize = cfg.Pool.MinSize
+		}
+		if cfg.Pool.MaxSize > 0 {
+			result.Pool.MaxSize = cfg.Pool.MaxSize
+		}
+		if result.Pool.MaxSize < result.Pool.MinSize {
+			result.Pool.MaxSize = result.Pool.MinSize
+		}
+		if cfg.Pool.IdleTimeout != 0 {
+			result.Pool.IdleTimeout = cfg.Pool.IdleTimeout
+		}
+	}
+
+	if cfg.Retry != nil {
+		result.Retry.Disabled = cfg.Retry.Disabled
+		if cfg.Retry.MaxRetries > 0 {
+			result.Retry.MaxRetries = cfg.Retry.MaxRetries
+		}
+		if cfg.Retry.InitialBackoff != 0 {
+			result.Retry.InitialBackoff = cfg.Retry.InitialBackoff
+		}
+		if cfg.Retry.MaxBackoff != 0 {
+			result.Retry.MaxBackoff = cfg.Retry.MaxBackoff
+		}
+	}
 
 	if cfg.Users != nil {
 		result.Users.SearchBase = cfg.Users.SearchBase
@@ -131,6 +256,9 @@ func populateConfig(cfg *Config) *Config {
 		if cfg.Users.FilterGroupsByDn != "" {
 			result.Users.FilterGroupsByDn = cfg.Users.FilterGroupsByDn
 		}
+		if cfg.Users.FilterNestedGroupsByDn != "" {
+			result.Users.FilterNestedGroupsByDn = cfg.Users.FilterNestedGroupsByDn
+		}
 	}
 
 	if cfg.Groups != nil {
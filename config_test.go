@@ -34,6 +34,14 @@ func Test_AppendGroupsAttributes(t *testing.T) {
 	require.Equal(t, []string{"one", "two"}, cfg.Groups.Attributes)
 }
 
+func Test_Config_serverURLs(t *testing.T) {
+	require.Equal(t, []string{""}, (&Config{}).serverURLs())
+	require.Equal(t, []string{"ldap://dc1"}, (&Config{URL: "ldap://dc1"}).serverURLs())
+	require.Equal(t, []string{"ldap://dc1", "ldap://dc2"}, (&Config{URLs: []string{"ldap://dc1", "ldap://dc2"}}).serverURLs())
+	require.Equal(t, []string{"ldap://dc1", "ldap://dc2"}, (&Config{URL: "ldap://dc1", URLs: []string{"ldap://dc2"}}).serverURLs())
+	require.Equal(t, []string{"ldap://dc2", "ldap://dc1"}, (&Config{URL: "ldap://dc1", URLs: []string{"ldap://dc2", "ldap://dc1"}}).serverURLs())
+}
+
 func Test_populateConfig(t *testing.T) {
 	defCfg := getDefaultConfig()
 
@@ -65,6 +73,11 @@ func Test_populateConfig(t *testing.T) {
 		require.NotNil(t, cfg)
 
 		require.Equal(t, defCfg.Timeout, cfg.Timeout)
+		require.Equal(t, defCfg.Pool, cfg.Pool)
+		require.Equal(t, defCfg.Retry, cfg.Retry)
+		require.Equal(t, defCfg.ServerSelection, cfg.ServerSelection)
+		require.Equal(t, defCfg.DialTimeout, cfg.DialTimeout)
+		require.Equal(t, defCfg.ServerCooldown, cfg.ServerCooldown)
 		require.Equal(t, customCfg.URL, cfg.URL)
 		require.Equal(t, defCfg.InsecureTLS, cfg.InsecureTLS)
 		require.Equal(t, customCfg.SearchBase, cfg.SearchBase)
@@ -76,6 +89,7 @@ func Test_populateConfig(t *testing.T) {
 		require.Equal(t, defCfg.Users.FilterById, cfg.Users.FilterById)
 		require.Equal(t, defCfg.Users.FilterByDn, cfg.Users.FilterByDn)
 		require.Equal(t, defCfg.Users.FilterGroupsByDn, cfg.Users.FilterGroupsByDn)
+		require.Equal(t, defCfg.Users.FilterNestedGroupsByDn, cfg.Users.FilterNestedGroupsByDn)
 
 		require.Equal(t, defCfg.Groups.IdAttribute, cfg.Groups.IdAttribute)
 		require.Equal(t, customCfg.Groups.SearchBase, cfg.Groups.SearchBase)
@@ -85,23 +99,54 @@ func Test_populateConfig(t *testing.T) {
 		require.Equal(t, defCfg.Groups.FilterMembersByDn, cfg.Groups.FilterMembersByDn)
 	})
 
+	t.Run("PoolMaxSizeBelowMin", func(t *testing.T) {
+		cfg := populateConfig(&Config{Pool: &PoolConfig{MinSize: 20}})
+		require.Equal(t, 20, cfg.Pool.MinSize)
+		require.Equal(t, 20, cfg.Pool.MaxSize)
+	})
+
 	t.Run("CustomConfigAll", func(t *testing.T) {
 		customCfg := &Config{
-			URL:         "ldaps://fakeurl:636",
-			InsecureTLS: true,
-			Timeout:     5 * time.Second,
+			URL:             "ldaps://fakeurl:636",
+			URLs:            []string{"ldaps://fakeurl2:636"},
+			ServerSelection: ServerSelectionRoundRobin,
+			DialTimeout:     time.Second,
+			ServerCooldown:  time.Hour,
+			Domain:          "company.com",
+			Site:            "HQ",
+			GlobalCatalog:   true,
+			InsecureTLS:     true,
+			TLSMode:         TLSModeLDAPS,
+			TLS:             &TLSConfig{ServerName: "dc"},
+			Timeout:         5 * time.Second,
+
+			DiscoveryRefresh: time.Hour,
+			Pool: &PoolConfig{
+				MinSize:     2,
+				MaxSize:     4,
+				IdleTimeout: time.Minute,
+			},
+			Retry: &RetryConfig{
+				Disabled:       true,
+				MaxRetries:     5,
+				InitialBackoff: time.Second,
+				MaxBackoff:     time.Minute,
+			},
 			Bind: &BindAccount{
-				DN:       "some",
-				Password: "fake",
+				DN:        `COMPANY\some`,
+				Password:  "fake",
+				Mechanism: BindNTLM,
 			},
-			SearchBase: "OU=some",
+			AuthMechanism: BindNTLM,
+			SearchBase:    "OU=some",
 			Users: &UsersConfigs{
-				IdAttribute:      "custom-users-id-attr",
-				Attributes:       []string{"dummy-user-attr"},
-				SearchBase:       "OU=custom-users",
-				FilterById:       "customFilterById",
-				FilterByDn:       "customFilterByDn",
-				FilterGroupsByDn: "customFilterGroupsByDn",
+				IdAttribute:            "custom-users-id-attr",
+				Attributes:             []string{"dummy-user-attr"},
+				SearchBase:             "OU=custom-users",
+				FilterById:             "customFilterById",
+				FilterByDn:             "customFilterByDn",
+				FilterGroupsByDn:       "customFilterGroupsByDn",
+				FilterNestedGroupsByDn: "customFilterNestedGroupsByDn",
 			},
 			Groups: &GroupsConfigs{
 				IdAttribute:       "custom-groups-id-attr",
@@ -117,10 +162,23 @@ func Test_populateConfig(t *testing.T) {
 		require.NotNil(t, cfg)
 
 		require.Equal(t, customCfg.Timeout, cfg.Timeout)
+		require.Equal(t, customCfg.Pool, cfg.Pool)
+		require.Equal(t, customCfg.Retry, cfg.Retry)
 		require.Equal(t, customCfg.URL, cfg.URL)
 		require.Equal(t, customCfg.InsecureTLS, cfg.InsecureTLS)
+		require.Equal(t, customCfg.TLSMode, cfg.TLSMode)
+		require.Equal(t, customCfg.TLS, cfg.TLS)
+		require.Equal(t, customCfg.URLs, cfg.URLs)
+		require.Equal(t, customCfg.ServerSelection, cfg.ServerSelection)
+		require.Equal(t, customCfg.DialTimeout, cfg.DialTimeout)
+		require.Equal(t, customCfg.ServerCooldown, cfg.ServerCooldown)
+		require.Equal(t, customCfg.Domain, cfg.Domain)
+		require.Equal(t, customCfg.Site, cfg.Site)
+		require.Equal(t, customCfg.GlobalCatalog, cfg.GlobalCatalog)
+		require.Equal(t, customCfg.DiscoveryRefresh, cfg.DiscoveryRefresh)
 		require.Equal(t, customCfg.SearchBase, cfg.SearchBase)
 		require.Equal(t, customCfg.Bind, cfg.Bind)
+		require.Equal(t, customCfg.AuthMechanism, cfg.AuthMechanism)
 
 		require.Equal(t, customCfg.Users.IdAttribute, cfg.Users.IdAttribute)
 		require.Equal(t, customCfg.Users.SearchBase, cfg.Users.SearchBase)
@@ -128,6 +186,7 @@ func Test_populateConfig(t *testing.T) {
 		require.Equal(t, customCfg.Users.FilterById, cfg.Users.FilterById)
 		require.Equal(t, customCfg.Users.FilterByDn, cfg.Users.FilterByDn)
 		require.Equal(t, customCfg.Users.FilterGroupsByDn, cfg.Users.FilterGroupsByDn)
+		require.Equal(t, customCfg.Users.FilterNestedGroupsByDn, cfg.Users.FilterNestedGroupsByDn)
 
 		require.Equal(t, customCfg.Groups.IdAttribute, cfg.Groups.IdAttribute)
 		require.Equal(t, customCfg.Groups.SearchBase, cfg.Groups.SearchBase)
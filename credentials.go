@@ -0,0 +1,132 @@
+package adc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const redactedPassword = "[REDACTED]"
+
+// Provides bind account password, e.g. from secrets store with password rotation.
+type CredentialProvider interface {
+	Password(ctx context.Context) (string, error)
+}
+
+// Adapter to use ordinary function as credential provider.
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+func (f CredentialProviderFunc) Password(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Reads password from environment variable.
+type EnvCredentials struct {
+	Variable string
+}
+
+func (c *EnvCredentials) Password(ctx context.Context) (string, error) {
+	password, ok := os.LookupEnv(c.Variable)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", c.Variable)
+	}
+	return password, nil
+}
+
+// Reads password from file. Trailing line break is trimmed.
+type FileCredentials struct {
+	Path string
+}
+
+func (c *FileCredentials) Password(ctx context.Context) (string, error) {
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Runs command and reads password from its output. Trailing line break is trimmed.
+type ExecCredentials struct {
+	Command string
+	Args    []string
+}
+
+func (c *ExecCredentials) Password(ctx context.Context) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// Hides secrets from JSON output.
+func (b BindAccount) MarshalJSON() ([]byte, error) {
+	type account BindAccount
+	a := account(b)
+	a.Password = redact(a.Password)
+	a.NTHash = redact(a.NTHash)
+	return json.Marshal(a)
+}
+
+// Hides secrets from log output.
+func (b BindAccount) String() string {
+	return fmt.Sprintf("{DN:%s Password:%s Mechanism:%s NTHash:%s}", b.DN, redact(b.Password), b.Mechanism, redact(b.NTHash))
+}
+
+func (b BindAccount) GoString() string {
+	return "adc.BindAccount" + b.String()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedPassword
+}
+
+// Caches password from credential provider between connects.
+type credentials struct {
+	mu       sync.Mutex
+	password string
+	fetched  bool
+}
+
+// Makes the next bind fetch password from credential provider again.
+func (c *credentials) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetched = false
+}
+
+// Returns bind account password. Password is fetched from account credential provider if it is set
+// and cached password is invalidated or refresh is requested.
+func (c *credentials) get(ctx context.Context, account *BindAccount, refresh bool) (string, error) {
+	if account.Credentials == nil {
+		return account.Password, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fetched && !refresh {
+		return c.password, nil
+	}
+	password, err := account.Credentials.Password(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bind credentials: %w", err)
+	}
+	c.password = password
+	c.fetched = true
+	return password, nil
+}
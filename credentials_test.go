@@ -0,0 +1,114 @@
+package adc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CredentialProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("ADC_TEST_BIND_PASSWORD", "validPass")
+		password, err := (&EnvCredentials{Variable: "ADC_TEST_BIND_PASSWORD"}).Password(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "validPass", password)
+
+		_, err = (&EnvCredentials{Variable: "ADC_TEST_NOT_SET"}).Password(ctx)
+		require.Error(t, err)
+	})
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte("valid Pass\n"), 0o600))
+		password, err := (&FileCredentials{Path: path}).Password(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "valid Pass", password)
+
+		_, err = (&FileCredentials{Path: filepath.Join(t.TempDir(), "missing")}).Password(ctx)
+		require.Error(t, err)
+	})
+	t.Run("Exec", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("requires sh")
+		}
+		password, err := (&ExecCredentials{Command: "sh", Args: []string{"-c", "echo validPass"}}).Password(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "validPass", password)
+
+		_, err = (&ExecCredentials{Command: "sh", Args: []string{"-c", "echo vault is sealed >&2; exit 1"}}).Password(ctx)
+		require.ErrorContains(t, err, "vault is sealed")
+	})
+}
+
+func Test_Client_bind(t *testing.T) {
+	t.Run("ConsultedOnConnect", func(t *testing.T) {
+		calls := &atomic.Int32{}
+		provider := CredentialProviderFunc(func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return validMockBind.Password, nil
+		})
+		cl := newMockClient(&Config{
+			Bind: &BindAccount{DN: validMockBind.DN, Credentials: provider},
+			Pool: &PoolConfig{MinSize: 2},
+		})
+		require.NoError(t, cl.Connect())
+		require.Equal(t, int32(1), calls.Load(), "Password should be cached between pool connections")
+		require.NoError(t, cl.Connect())
+		require.Equal(t, int32(2), calls.Load())
+		require.NoError(t, cl.Disconnect())
+	})
+	t.Run("Rotated", func(t *testing.T) {
+		calls := &atomic.Int32{}
+		provider := CredentialProviderFunc(func(ctx context.Context) (string, error) {
+			if calls.Add(1) == 1 {
+				return "oldPass", nil
+			}
+			return validMockBind.Password, nil
+		})
+		cl := newMockClient(&Config{Bind: &BindAccount{DN: validMockBind.DN, Credentials: provider}})
+		require.NoError(t, cl.Connect())
+		require.Equal(t, int32(2), calls.Load())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		provider := CredentialProviderFunc(func(ctx context.Context) (string, error) { return "badPass", nil })
+		cl := newMockClient(&Config{Bind: &BindAccount{DN: validMockBind.DN, Credentials: provider}})
+		require.Error(t, cl.Connect())
+	})
+	t.Run("ProviderErr", func(t *testing.T) {
+		provider := CredentialProviderFunc(func(ctx context.Context) (string, error) { return "", errors.New("vault is sealed") })
+		cl := newMockClient(&Config{Bind: &BindAccount{DN: validMockBind.DN, Credentials: provider}})
+		require.ErrorContains(t, cl.Connect(), "vault is sealed")
+	})
+}
+
+func Test_BindAccount_redacted(t *testing.T) {
+	cfg := &Config{Bind: &BindAccount{DN: "validUser", Password: "secretPass", NTHash: "secretHash"}}
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secretPass")
+	require.NotContains(t, string(data), "secretHash")
+	require.Contains(t, string(data), `"dn":"validUser"`)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		out := fmt.Sprintf(format, cfg.Bind)
+		require.NotContains(t, out, "secretPass", format)
+		require.NotContains(t, out, "secretHash", format)
+		require.Contains(t, out, "validUser", format)
+	}
+	require.NotContains(t, fmt.Sprintf("%+v", *cfg.Bind), "secretPass")
+
+	// Password is still loaded from JSON.
+	var account BindAccount
+	require.NoError(t, json.Unmarshal([]byte(`{"dn":"validUser","password":"secretPass"}`), &account))
+	require.Equal(t, "secretPass", account.Password)
+}
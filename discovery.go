@@ -0,0 +1,172 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolves DNS SRV records. Implemented by *net.Resolver.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// Specifies custom DNS resolver for domain controllers discovery.
+func WithResolver(r Resolver) Option {
+	return func(cl *Client) { cl.discovery.resolver = r }
+}
+
+// Discovers domain controllers via DNS SRV records and caches results.
+type discovery struct {
+	resolver Resolver
+
+	mu      sync.Mutex
+	urls    []string
+	expires time.Time
+}
+
+func newDiscovery() *discovery {
+	return &discovery{resolver: net.DefaultResolver}
+}
+
+// Returns SRV record names to query in order of preference.
+func srvRecordNames(domain, site string, gc bool) []string {
+	var names []string
+	if gc {
+		if site != "" {
+			names = append(names, fmt.Sprintf("_gc._tcp.%s._sites.%s", site, domain))
+		}
+		return append(names, "_gc._tcp."+domain)
+	}
+	if site != "" {
+		names = append(names, fmt.Sprintf("_ldap._tcp.%s._sites.dc._msdcs.%s", site, domain))
+	}
+	return append(names, "_ldap._tcp.dc._msdcs."+domain, "_ldap._tcp."+domain)
+}
+
+// Returns discovered servers URLs. Cached results are used until refresh interval passes.
+// Stale results are used if discovery fails.
+func (d *discovery) lookup(ctx context.Context, cfg *Config) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.urls) > 0 && time.Now().Before(d.expires) {
+		return d.urls, nil
+	}
+
+	urls, err := d.resolve(ctx, cfg)
+	if err != nil {
+		if len(d.urls) > 0 {
+			return d.urls, nil
+		}
+		return nil, err
+	}
+	d.urls = urls
+	d.expires = time.Now().Add(cfg.DiscoveryRefresh)
+	return urls, nil
+}
+
+// Reports whether discovered servers are connected with LDAPS. If TLS mode isn't set, LDAPS is used when any
+// configured server uses it, so failover to discovered servers doesn't send credentials in cleartext.
+func discoverLDAPS(cfg *Config) bool {
+	if cfg.TLSMode != "" {
+		return cfg.TLSMode == TLSModeLDAPS
+	}
+	return slices.ContainsFunc(cfg.serverURLs(), func(u string) bool { return strings.HasPrefix(u, "ldaps://") })
+}
+
+func (d *discovery) resolve(ctx context.Context, cfg *Config) ([]string, error) {
+	ldaps := discoverLDAPS(cfg)
+
+	var urls []string
+	var lastErr error
+	for _, name := range srvRecordNames(cfg.Domain, cfg.Site, cfg.GlobalCatalog) {
+		_, records, err := d.resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, r := range orderSRV(records) {
+			u := srvURL(r, ldaps)
+			if !slices.Contains(urls, u) {
+				urls = append(urls, u)
+			}
+		}
+	}
+	if len(urls) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no SRV records found for domain '%s'", cfg.Domain)
+		}
+		return nil, fmt.Errorf("domain controllers discovery failed: %w", lastErr)
+	}
+	return urls, nil
+}
+
+// Orders SRV records by priority and randomly by weight within the same priority as RFC 2782 describes.
+func orderSRV(records []*net.SRV) []*net.SRV {
+	sorted := make([]*net.SRV, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority < sorted[j].Priority })
+
+	result := make([]*net.SRV, 0, len(sorted))
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+			end++
+		}
+		result = append(result, shuffleByWeight(sorted[start:end])...)
+		start = end
+	}
+	return result
+}
+
+func shuffleByWeight(records []*net.SRV) []*net.SRV {
+	left := make([]*net.SRV, len(records))
+	copy(left, records)
+
+	result := make([]*net.SRV, 0, len(records))
+	for len(left) > 0 {
+		total := 0
+		for _, r := range left {
+			total += int(r.Weight)
+		}
+		pick := 0
+		if total > 0 {
+			n := rand.Intn(total + 1)
+			for i, r := range left {
+				n -= int(r.Weight)
+				if n <= 0 {
+					pick = i
+					break
+				}
+			}
+		}
+		result = append(result, left[pick])
+		left = append(left[:pick], left[pick+1:]...)
+	}
+	return result
+}
+
+// Builds server URL from SRV record. LDAP ports are replaced with LDAPS ones for TLS connection.
+func srvURL(r *net.SRV, ldaps bool) string {
+	host := strings.TrimSuffix(r.Target, ".")
+	port := r.Port
+	scheme := "ldap"
+	if ldaps {
+		scheme = "ldaps"
+		switch port {
+		case 389:
+			port = 636
+		case 3268:
+			port = 3269
+		}
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(port))))
+}
@@ -0,0 +1,272 @@
+package adc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testResolver struct {
+	records map[string][]*net.SRV
+	calls   atomic.Int32
+	err     error
+}
+
+func (r *testResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.calls.Add(1)
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, records, nil
+}
+
+// Starts local DNS server that answers SRV queries with provided records.
+func startTestDNSServer(t *testing.T, records map[string][]*net.SRV) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := testDNSResponse(buf[:n], records); resp != nil {
+				conn.WriteTo(resp, addr) //nolint:errcheck
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func testDNSResponse(query []byte, records map[string][]*net.SRV) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// Parse question name.
+	var labels []string
+	pos := 12
+	for pos < len(query) && query[pos] != 0 {
+		l := int(query[pos])
+		labels = append(labels, string(query[pos+1:pos+1+l]))
+		pos += l + 1
+	}
+	pos += 5 // Zero label, type and class.
+	if pos > len(query) {
+		return nil
+	}
+	question := query[12:pos]
+	qtype := binary.BigEndian.Uint16(query[pos-4 : pos-2])
+
+	var answers []*net.SRV
+	if qtype == 33 {
+		answers = records[strings.Join(labels, ".")+"."]
+	}
+
+	resp := make([]byte, 12, 512)
+	copy(resp, query[:2])
+	flags := uint16(0x8180)
+	if len(answers) == 0 {
+		flags |= 3 // NXDOMAIN
+	}
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(answers)))
+	resp = append(resp, question...)
+
+	for _, r := range answers {
+		var target []byte
+		for _, l := range strings.Split(strings.TrimSuffix(r.Target, "."), ".") {
+			target = append(target, byte(len(l)))
+			target = append(target, l...)
+		}
+		target = append(target, 0)
+
+		rr := []byte{0xC0, 12, 0, 33, 0, 1, 0, 0, 0, 60}
+		rr = binary.BigEndian.AppendUint16(rr, uint16(6+len(target)))
+		rr = binary.BigEndian.AppendUint16(rr, r.Priority)
+		rr = binary.BigEndian.AppendUint16(rr, r.Weight)
+		rr = binary.BigEndian.AppendUint16(rr, r.Port)
+		resp = append(resp, rr...)
+		resp = append(resp, target...)
+	}
+	return resp
+}
+
+func Test_srvRecordNames(t *testing.T) {
+	require.Equal(t,
+		[]string{"_ldap._tcp.dc._msdcs.company.com", "_ldap._tcp.company.com"},
+		srvRecordNames("company.com", "", false),
+	)
+	require.Equal(t,
+		[]string{"_ldap._tcp.HQ._sites.dc._msdcs.company.com", "_ldap._tcp.dc._msdcs.company.com", "_ldap._tcp.company.com"},
+		srvRecordNames("company.com", "HQ", false),
+	)
+	require.Equal(t, []string{"_gc._tcp.company.com"}, srvRecordNames("company.com", "", true))
+	require.Equal(t,
+		[]string{"_gc._tcp.HQ._sites.company.com", "_gc._tcp.company.com"},
+		srvRecordNames("company.com", "HQ", true),
+	)
+}
+
+func Test_orderSRV(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "dc3", Priority: 10, Weight: 0},
+		{Target: "dc1", Priority: 0, Weight: 100},
+		{Target: "dc4", Priority: 20, Weight: 100},
+		{Target: "dc2", Priority: 0, Weight: 0},
+	}
+	heavyFirst := 0
+	for i := 0; i < 100; i++ {
+		ordered := orderSRV(records)
+		require.Len(t, ordered, 4)
+		require.ElementsMatch(t, []string{"dc1", "dc2"}, []string{ordered[0].Target, ordered[1].Target})
+		require.Equal(t, "dc3", ordered[2].Target)
+		require.Equal(t, "dc4", ordered[3].Target)
+		if ordered[0].Target == "dc1" {
+			heavyFirst++
+		}
+	}
+	require.Greater(t, heavyFirst, 90, "Record with higher weight should be preferred")
+}
+
+func Test_srvURL(t *testing.T) {
+	require.Equal(t, "ldap://dc1.company.com:389", srvURL(&net.SRV{Target: "dc1.company.com.", Port: 389}, false))
+	require.Equal(t, "ldaps://dc1.company.com:636", srvURL(&net.SRV{Target: "dc1.company.com.", Port: 389}, true))
+	require.Equal(t, "ldaps://gc.company.com:3269", srvURL(&net.SRV{Target: "gc.company.com.", Port: 3268}, true))
+	require.Equal(t, "ldap://gc.company.com:3268", srvURL(&net.SRV{Target: "gc.company.com.", Port: 3268}, false))
+}
+
+func Test_discovery_lookup(t *testing.T) {
+	records := map[string][]*net.SRV{
+		"_ldap._tcp.HQ._sites.dc._msdcs.company.com": {{Target: "dc-hq.company.com.", Port: 389}},
+		"_ldap._tcp.dc._msdcs.company.com": {
+			{Target: "dc2.company.com.", Port: 389, Priority: 10},
+			{Target: "dc1.company.com.", Port: 389, Priority: 0},
+			{Target: "dc-hq.company.com.", Port: 389, Priority: 0},
+		},
+	}
+
+	t.Run("Site", func(t *testing.T) {
+		d := &discovery{resolver: &testResolver{records: records}}
+		urls, err := d.lookup(context.Background(), &Config{Domain: "company.com", Site: "HQ", DiscoveryRefresh: time.Minute})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldap://dc-hq.company.com:389", "ldap://dc1.company.com:389", "ldap://dc2.company.com:389"}, urls)
+	})
+	t.Run("Cache", func(t *testing.T) {
+		r := &testResolver{records: records}
+		d := &discovery{resolver: r}
+		cfg := &Config{Domain: "company.com", TLSMode: TLSModeLDAPS, DiscoveryRefresh: time.Minute}
+
+		urls, err := d.lookup(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Len(t, urls, 3)
+		require.Equal(t, "ldaps://dc2.company.com:636", urls[2])
+		calls := r.calls.Load()
+
+		_, err = d.lookup(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, calls, r.calls.Load(), "Cached results should be used")
+
+		// Stale results are used on failure.
+		d.expires = time.Now().Add(-time.Second)
+		r.err = errors.New("dns error")
+		stale, err := d.lookup(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, urls, stale)
+	})
+	t.Run("LDAPSFromURL", func(t *testing.T) {
+		d := &discovery{resolver: &testResolver{records: records}}
+		urls, err := d.lookup(context.Background(), &Config{URL: "ldaps://dc1.company.com:636", Domain: "company.com"})
+		require.NoError(t, err)
+		require.Equal(t, "ldaps://dc2.company.com:636", urls[2])
+
+		d = &discovery{resolver: &testResolver{records: records}}
+		urls, err = d.lookup(context.Background(), &Config{URL: "ldap://dc1.company.com:389", Domain: "company.com", TLSMode: TLSModeStartTLSRequired})
+		require.NoError(t, err)
+		require.Equal(t, "ldap://dc2.company.com:389", urls[2], "TLS mode has priority over URL scheme")
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		d := &discovery{resolver: &testResolver{records: records}}
+		_, err := d.lookup(context.Background(), &Config{Domain: "other.com"})
+		require.Error(t, err)
+	})
+	t.Run("StubDNSServer", func(t *testing.T) {
+		addr := startTestDNSServer(t, map[string][]*net.SRV{
+			"_ldap._tcp.dc._msdcs.company.test.": {
+				{Target: "dc2.company.test.", Port: 389, Priority: 10, Weight: 100},
+				{Target: "dc1.company.test.", Port: 389, Priority: 0, Weight: 100},
+			},
+		})
+		resolver := &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "udp", addr)
+			},
+		}
+
+		cl := newMockClient(&Config{Domain: "company.test"}, WithResolver(resolver))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		urls, err := cl.serverURLs(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldap://dc1.company.test:389", "ldap://dc2.company.test:389"}, urls)
+	})
+}
+
+func Test_Client_serverURLs(t *testing.T) {
+	resolver := &testResolver{records: map[string][]*net.SRV{
+		"_ldap._tcp.dc._msdcs.company.com": {{Target: "dc1.company.com.", Port: 389}, {Target: "dc2.company.com.", Port: 389}},
+	}}
+
+	t.Run("WithoutDomain", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://static:389"}, WithResolver(resolver))
+		urls, err := cl.serverURLs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldap://static:389"}, urls)
+	})
+	t.Run("WithStatic", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://dc2.company.com:389", Domain: "company.com"}, WithResolver(resolver))
+		urls, err := cl.serverURLs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldap://dc2.company.com:389", "ldap://dc1.company.com:389"}, urls)
+	})
+	t.Run("LDAPSFailover", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldaps://dc1.company.com:636", Domain: "company.com"}, WithResolver(resolver))
+		urls, err := cl.serverURLs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldaps://dc1.company.com:636", "ldaps://dc2.company.com:636"}, urls)
+		for _, u := range urls {
+			require.Equal(t, TLSModeLDAPS, cl.Config.tlsMode(u), u)
+		}
+	})
+	t.Run("DiscoveryErr", func(t *testing.T) {
+		failing := &testResolver{err: errors.New("dns error")}
+
+		cl := newMockClient(&Config{Domain: "company.com", Bind: validMockBind}, WithResolver(failing))
+		require.Error(t, cl.Connect())
+
+		cl = newMockClient(&Config{URL: "ldap://static:389", Domain: "company.com"}, WithResolver(failing))
+		urls, err := cl.serverURLs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"ldap://static:389"}, urls)
+	})
+	t.Run("Connect", func(t *testing.T) {
+		cl := newMockClient(&Config{Domain: "company.com", Bind: validMockBind}, WithResolver(resolver))
+		require.NoError(t, cl.Connect())
+		require.Equal(t, "ldap://dc1.company.com:389", cl.CurrentServer())
+	})
+}
@@ -0,0 +1,144 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// LDAP matching rule that matches if all bits of the value are set in the attribute.
+const matchingRuleBitAnd = "1.2.840.113556.1.4.803"
+
+// Page size of searches that aren't limited by caller. AD returns at most 1000 entries per page by default.
+const defaultPageSize = 1000
+
+// User attributes required to compute password expiry.
+var passwordExpiryAttributes = []string{"userAccountControl", "pwdLastSet", "msDS-UserPasswordExpiryTimeComputed"}
+
+// Returns time when user password expires and whether it expires at all.
+// msDS-UserPasswordExpiryTimeComputed attribute is used if requested, otherwise expiry is computed
+// from pwdLastSet and provided policy MaxAge. Zero time with true means password must be changed at next logon.
+// Password never expires if userAccountControl has UACDontExpirePassword flag or policy MaxAge is zero.
+func (u *User) PasswordExpiresAt(policy *PasswordPolicy) (time.Time, bool) {
+	if u.UserAccountControl().Has(UACDontExpirePassword) {
+		return time.Time{}, false
+	}
+	if computed := stringAttribute(u.Attributes, "msDS-UserPasswordExpiryTimeComputed"); computed != "" {
+		if intAttribute(u.Attributes, "msDS-UserPasswordExpiryTimeComputed") == fileTimeNever {
+			return time.Time{}, false
+		}
+		return ParseTime(computed), true
+	}
+
+	lastSet := stringAttribute(u.Attributes, "pwdLastSet")
+	if lastSet == "" || policy == nil || policy.MaxAge == 0 {
+		return time.Time{}, false
+	}
+	if lastSet == "0" {
+		return time.Time{}, true
+	}
+	return ParseTime(lastSet).Add(policy.MaxAge), true
+}
+
+// Returns enabled users which passwords expire within provided period from now.
+// Users are filtered by the server with pwdLastSet according to default domain policy, and then
+// by computed expiry time, so users with fine-grained policy maximum age different from domain one may be missed.
+func (cl *Client) ListUsersWithPasswordExpiringWithin(d time.Duration) ([]User, error) {
+	return cl.ListUsersWithPasswordExpiringWithinContext(context.Background(), d)
+}
+
+// Context-aware version of ListUsersWithPasswordExpiringWithin.
+func (cl *Client) ListUsersWithPasswordExpiringWithinContext(ctx context.Context, d time.Duration) ([]User, error) {
+	policy, err := cl.GetPasswordPolicyContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get password policy: %w", err)
+	}
+	if policy.MaxAge == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	filter := fmt.Sprintf("(&%s(!(userAccountControl:%s:=%d))(!(userAccountControl:%s:=%d))(pwdLastSet>=%d)(pwdLastSet<=%d))",
+		cl.Config.Users.FilterByPerson,
+		matchingRuleBitAnd, UACAccountDisable,
+		matchingRuleBitAnd, UACDontExpirePassword,
+		TimeToFileTime(now.Add(-policy.MaxAge)),
+		TimeToFileTime(now.Add(d-policy.MaxAge)),
+	)
+	attributes := append(slices.Clone(cl.Config.Users.Attributes), passwordExpiryAttributes...)
+	users, err := cl.ListUsersContext(ctx, GetUserArgs{Attributes: attributes}, defaultPageSize, filter)
+	if err != nil || users == nil {
+		return nil, err
+	}
+
+	var result []User
+	for _, u := range *users {
+		expiresAt, expires := u.PasswordExpiresAt(policy)
+		if expires && !expiresAt.Before(now) && expiresAt.Before(now.Add(d)) {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+// Reports whether account is expired. accountExpires attribute must be requested.
+func (u *User) AccountExpired() bool {
+	expiresAt := u.AccountExpires()
+	return !expiresAt.IsZero() && !expiresAt.After(time.Now())
+}
+
+// Sets time when user account expires. Zero time means account never expires.
+func (cl *Client) SetAccountExpiry(dn string, expiresAt time.Time) error {
+	return cl.SetAccountExpiryContext(context.Background(), dn, expiresAt)
+}
+
+// Context-aware version of SetAccountExpiry.
+func (cl *Client) SetAccountExpiryContext(ctx context.Context, dn string, expiresAt time.Time) error {
+	value := int64(fileTimeNever)
+	if !expiresAt.IsZero() {
+		value = TimeToFileTime(expiresAt)
+	}
+	return cl.updateAttribute(ctx, dn, "accountExpires", []string{strconv.FormatInt(value, 10)})
+}
+
+// Clears user account expiry, so account never expires.
+func (cl *Client) ClearAccountExpiry(dn string) error {
+	return cl.ClearAccountExpiryContext(context.Background(), dn)
+}
+
+// Context-aware version of ClearAccountExpiry.
+func (cl *Client) ClearAccountExpiryContext(ctx context.Context, dn string) error {
+	return cl.SetAccountExpiryContext(ctx, dn, time.Time{})
+}
+
+// Returns users which accounts expire in provided period [from, to).
+// Use past period to get accounts that already expired, e.g. during the last week.
+func (cl *Client) ListUsersWithAccountExpiring(from, to time.Time) ([]User, error) {
+	return cl.ListUsersWithAccountExpiringContext(context.Background(), from, to)
+}
+
+// Context-aware version of ListUsersWithAccountExpiring.
+func (cl *Client) ListUsersWithAccountExpiringContext(ctx context.Context, from, to time.Time) ([]User, error) {
+	// Both 0 and maximum values that mean never are out of the range.
+	filter := fmt.Sprintf("(&%s(accountExpires>=%d)(accountExpires<=%d))",
+		cl.Config.Users.FilterByPerson,
+		max(TimeToFileTime(from), 1),
+		TimeToFileTime(to)-1,
+	)
+	attributes := append(slices.Clone(cl.Config.Users.Attributes), "accountExpires")
+	users, err := cl.ListUsersContext(ctx, GetUserArgs{Attributes: attributes}, defaultPageSize, filter)
+	if err != nil || users == nil {
+		return nil, err
+	}
+
+	var result []User
+	for _, u := range *users {
+		expiresAt := u.AccountExpires()
+		if !expiresAt.IsZero() && !expiresAt.Before(from) && expiresAt.Before(to) {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
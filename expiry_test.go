@@ -0,0 +1,149 @@
+package adc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func fileTimeValue(t time.Time) string {
+	return strconv.FormatInt(TimeToFileTime(t), 10)
+}
+
+func Test_User_PasswordExpiresAt(t *testing.T) {
+	lastSet := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &PasswordPolicy{MaxAge: 42 * 24 * time.Hour}
+
+	t.Run("Computed", func(t *testing.T) {
+		user := &User{Attributes: map[string]interface{}{
+			"pwdLastSet":                          fileTimeValue(lastSet),
+			"msDS-UserPasswordExpiryTimeComputed": fileTimeValue(lastSet.Add(90 * 24 * time.Hour)),
+		}}
+		expiresAt, expires := user.PasswordExpiresAt(policy)
+		require.True(t, expires)
+		require.Equal(t, lastSet.Add(90*24*time.Hour), expiresAt)
+
+		user.Attributes["msDS-UserPasswordExpiryTimeComputed"] = "9223372036854775807"
+		_, expires = user.PasswordExpiresAt(policy)
+		require.False(t, expires)
+	})
+	t.Run("Policy", func(t *testing.T) {
+		user := &User{Attributes: map[string]interface{}{"pwdLastSet": fileTimeValue(lastSet)}}
+		expiresAt, expires := user.PasswordExpiresAt(policy)
+		require.True(t, expires)
+		require.Equal(t, lastSet.Add(42*24*time.Hour), expiresAt)
+
+		_, expires = user.PasswordExpiresAt(&PasswordPolicy{})
+		require.False(t, expires, "Passwords never expire by policy")
+		_, expires = user.PasswordExpiresAt(nil)
+		require.False(t, expires)
+	})
+	t.Run("MustChange", func(t *testing.T) {
+		user := &User{Attributes: map[string]interface{}{"pwdLastSet": "0"}}
+		expiresAt, expires := user.PasswordExpiresAt(policy)
+		require.True(t, expires)
+		require.True(t, expiresAt.IsZero())
+	})
+	t.Run("NeverExpires", func(t *testing.T) {
+		user := &User{Attributes: map[string]interface{}{
+			"pwdLastSet":         fileTimeValue(lastSet),
+			"userAccountControl": "66048",
+		}}
+		_, expires := user.PasswordExpiresAt(policy)
+		require.False(t, expires)
+	})
+	t.Run("NotRequested", func(t *testing.T) {
+		_, expires := (&User{}).PasswordExpiresAt(policy)
+		require.False(t, expires)
+	})
+}
+
+func Test_Client_ListUsersWithPasswordExpiringWithin(t *testing.T) {
+	user2DN := "OU=user2,DC=company,DC=com"
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	// Password set 40 days ago expires in 2 days by domain policy.
+	require.NoError(t, cl.UpdateUser(user2DN, []ldap.Attribute{
+		{Type: "pwdLastSet", Vals: []string{fileTimeValue(time.Now().Add(-40 * 24 * time.Hour))}},
+	}))
+	users, err := cl.ListUsersWithPasswordExpiringWithin(7 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user2DN, users[0].DN)
+
+	users, err = cl.ListUsersWithPasswordExpiringWithin(24 * time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	// Computed expiry of fine-grained policy is preferred.
+	require.NoError(t, cl.UpdateUser(user2DN, []ldap.Attribute{
+		{Type: "msDS-UserPasswordExpiryTimeComputed", Vals: []string{fileTimeValue(time.Now().Add(50 * 24 * time.Hour))}},
+	}))
+	users, err = cl.ListUsersWithPasswordExpiringWithin(7 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cl.ListUsersWithPasswordExpiringWithinContext(ctx, time.Hour)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_User_AccountExpired(t *testing.T) {
+	user := &User{Attributes: map[string]interface{}{"accountExpires": "9223372036854775807"}}
+	require.False(t, user.AccountExpired())
+	user.Attributes["accountExpires"] = "0"
+	require.False(t, user.AccountExpired())
+	user.Attributes["accountExpires"] = fileTimeValue(time.Now().Add(-time.Hour))
+	require.True(t, user.AccountExpired())
+	user.Attributes["accountExpires"] = fileTimeValue(time.Now().Add(time.Hour))
+	require.False(t, user.AccountExpired())
+}
+
+func Test_Client_AccountExpiry(t *testing.T) {
+	user1DN := "OU=user1,DC=company,DC=com"
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	getExpiry := func(t *testing.T) string {
+		t.Helper()
+		entry, err := cl.readEntry(context.Background(), user1DN, []string{"accountExpires"})
+		require.NoError(t, err)
+		return entry.GetAttributeValue("accountExpires")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(3 * 24 * time.Hour).Truncate(time.Second)
+	t.Run("Set", func(t *testing.T) {
+		require.NoError(t, cl.SetAccountExpiry(user1DN, expiresAt))
+		require.Equal(t, fileTimeValue(expiresAt), getExpiry(t))
+	})
+	t.Run("List", func(t *testing.T) {
+		users, err := cl.ListUsersWithAccountExpiring(now, now.Add(7*24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, user1DN, users[0].DN)
+		require.True(t, expiresAt.Equal(users[0].AccountExpires()))
+		require.False(t, users[0].AccountExpired())
+
+		users, err = cl.ListUsersWithAccountExpiring(now.Add(-7*24*time.Hour), now)
+		require.NoError(t, err)
+		require.Empty(t, users)
+	})
+	t.Run("Clear", func(t *testing.T) {
+		require.NoError(t, cl.ClearAccountExpiry(user1DN))
+		require.Equal(t, "9223372036854775807", getExpiry(t))
+
+		users, err := cl.ListUsersWithAccountExpiring(now, now.Add(7*24*time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, users)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		require.Error(t, cl.SetAccountExpiry("OU=unknown,DC=company,DC=com", expiresAt))
+	})
+}
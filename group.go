@@ -1,10 +1,12 @@
 package adc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 )
@@ -21,6 +23,8 @@ type Group struct {
 type GroupMember struct {
 	DN string `json:"dn"`
 	Id string `json:"id"`
+	// The group is member primary group, so member isn't listed in the group 'member' attribute.
+	Primary bool `json:"primary"`
 }
 
 // Returns string attribute by attribute name.
@@ -36,11 +40,43 @@ func (g *Group) GetStringAttribute(name string) string {
 	return ""
 }
 
+// Returns time attribute in FILETIME or GeneralizedTime format by attribute name.
+// Returns zero time if attribute not exists, can't be parsed or means never.
+func (g *Group) GetTimeAttribute(name string) time.Time {
+	return ParseTime(stringAttribute(g.Attributes, name))
+}
+
+// Returns objectGUID in canonical form. Attribute must be requested.
+func (g *Group) GUID() string {
+	return stringAttribute(g.Attributes, "objectGUID")
+}
+
+// Returns objectSid in 'S-1-5-...' form. Attribute must be requested.
+func (g *Group) SID() string {
+	return stringAttribute(g.Attributes, "objectSid")
+}
+
+// Returns whenCreated time.
+func (g *Group) WhenCreated() time.Time {
+	return g.GetTimeAttribute("whenCreated")
+}
+
+// Returns whenChanged time.
+func (g *Group) WhenChanged() time.Time {
+	return g.GetTimeAttribute("whenChanged")
+}
+
 type GetGroupArgs struct {
 	// Group ID to search.
 	Id string `json:"id"`
 	// Optional group DN. Overwrites ID if provided in request.
 	Dn string `json:"dn"`
+	// Optional objectGUID in canonical form. Overwrites ID if provided in request and survives renames and moves.
+	GUID string `json:"guid"`
+	// Optional objectSid in 'S-1-5-...' form. Overwrites ID if provided in request.
+	SID string `json:"sid"`
+	// Optional mail. Overwrites ID if provided in request.
+	Mail string `json:"mail"`
 	// Optional LDAP filter to search entry. Warning! provided Filter arg overwrites Id and Dn args usage.
 	Filter string `json:"filter"`
 	// Optional group attributes to overwrite attributes in client config.
@@ -50,13 +86,18 @@ type GetGroupArgs struct {
 }
 
 func (args GetGroupArgs) Validate() error {
-	if args.Id == "" && args.Dn == "" && args.Filter == "" {
-		return errors.New("neither of ID, DN or Filter provided")
+	if args.Id == "" && args.Dn == "" && args.Filter == "" && args.GUID == "" && args.SID == "" && args.Mail == "" {
+		return errors.New("neither of ID, DN, GUID, SID, Mail or Filter provided")
 	}
 	return nil
 }
 
 func (cl *Client) GetGroup(args GetGroupArgs) (*Group, error) {
+	return cl.GetGroupContext(context.Background(), args)
+}
+
+// Context-aware version of GetGroup.
+func (cl *Client) GetGroupContext(ctx context.Context, args GetGroupArgs) (*Group, error) {
 	if err := args.Validate(); err != nil {
 		return nil, err
 	}
@@ -65,6 +106,13 @@ func (cl *Client) GetGroup(args GetGroupArgs) (*Group, error) {
 		filter = args.Filter
 	} else {
 		filter = fmt.Sprintf(cl.Config.Groups.FilterById, args.Id)
+		lookup, err := lookupFilter(cl.Config.Groups.FilterByGroup, args.GUID, args.SID, "", args.Mail)
+		if err != nil {
+			return nil, err
+		}
+		if lookup != "" {
+			filter = lookup
+		}
 		if args.Dn != "" {
 			filter = fmt.Sprintf(cl.Config.Groups.FilterByDn, ldap.EscapeFilter(args.Dn))
 		}
@@ -82,7 +130,7 @@ func (cl *Client) GetGroup(args GetGroupArgs) (*Group, error) {
 		req.Attributes = args.Attributes
 	}
 
-	entry, err := cl.searchEntry(req)
+	entry, err := cl.searchEntry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -93,16 +141,13 @@ func (cl *Client) GetGroup(args GetGroupArgs) (*Group, error) {
 	result := &Group{
 		DN:         entry.DN,
 		Id:         entry.GetAttributeValue(cl.Config.Groups.IdAttribute),
-		Attributes: make(map[string]interface{}, len(entry.Attributes)),
-	}
-	for _, a := range entry.Attributes {
-		result.Attributes[a.Name] = entry.GetAttributeValue(a.Name)
+		Attributes: entryAttributes(entry),
 	}
 
 	if !args.SkipMembersSearch {
-		members, err := cl.getGroupMembers(entry.DN)
+		members, err := cl.getGroupMembers(ctx, entry.DN)
 		if err != nil {
-			return nil, fmt.Errorf("can't get group members: %s", err.Error())
+			return nil, fmt.Errorf("can't get group members: %w", err)
 		}
 		result.Members = members
 	}
@@ -111,6 +156,11 @@ func (cl *Client) GetGroup(args GetGroupArgs) (*Group, error) {
 }
 
 func (cl *Client) ListGroups(args GetGroupArgs, pageSize int, filter string) (*[]Group, error) {
+	return cl.ListGroupsContext(context.Background(), args, pageSize, filter)
+}
+
+// Context-aware version of ListGroups.
+func (cl *Client) ListGroupsContext(ctx context.Context, args GetGroupArgs, pageSize int, filter string) (*[]Group, error) {
 	req := &ldap.SearchRequest{
 		BaseDN:       cl.Config.Groups.SearchBase,
 		Scope:        ldap.ScopeWholeSubtree,
@@ -126,33 +176,9 @@ func (cl *Client) ListGroups(args GetGroupArgs, pageSize int, filter string) (*[
 		req.Filter = filter
 	}
 
-	control := ldap.NewControlPaging(uint32(pageSize))
-	var entries []*ldap.Entry
-
-	for {
-		req.Controls = []ldap.Control{control}
-
-		sr, err := cl.ldap.Search(req)
-		if err != nil {
-			return nil, err
-		}
-
-		entries = append(entries, sr.Entries...)
-
-		if sr.Controls == nil {
-			break
-		}
-
-		pagingControl, ok := sr.Controls[0].(*ldap.ControlPaging)
-		if !ok {
-			break
-		}
-
-		if len(pagingControl.Cookie) == 0 {
-			break
-		}
-
-		control.SetCookie(pagingControl.Cookie)
+	entries, err := cl.searchPaged(ctx, req, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	if entries == nil {
@@ -164,10 +190,7 @@ func (cl *Client) ListGroups(args GetGroupArgs, pageSize int, filter string) (*[
 		result := &Group{
 			DN:         entry.DN,
 			Id:         entry.GetAttributeValue(cl.Config.Users.IdAttribute),
-			Attributes: make(map[string]interface{}, len(entry.Attributes)),
-		}
-		for _, a := range entry.Attributes {
-			result.Attributes[a.Name] = entry.GetAttributeValue(a.Name)
+			Attributes: entryAttributes(entry),
 		}
 		results = append(results, *result)
 	}
@@ -175,26 +198,41 @@ func (cl *Client) ListGroups(args GetGroupArgs, pageSize int, filter string) (*[
 }
 
 func (cl *Client) CreateGroup(dn string, groupAttrs []ldap.Attribute) error {
+	return cl.CreateGroupContext(context.Background(), dn, groupAttrs)
+}
+
+// Context-aware version of CreateGroup.
+func (cl *Client) CreateGroupContext(ctx context.Context, dn string, groupAttrs []ldap.Attribute) error {
 	addReq := ldap.NewAddRequest(dn, []ldap.Control{})
 	addReq.Attributes = groupAttrs
 
-	return cl.addRequest(addReq)
+	return cl.addRequest(ctx, addReq)
 }
 
 func (cl *Client) DeleteGroup(dn string) error {
+	return cl.DeleteGroupContext(context.Background(), dn)
+}
+
+// Context-aware version of DeleteGroup.
+func (cl *Client) DeleteGroupContext(ctx context.Context, dn string) error {
 	delReq := ldap.NewDelRequest(dn, []ldap.Control{})
 
-	return cl.deleteRequest(delReq)
+	return cl.deleteRequest(ctx, delReq)
 }
 
 func (cl *Client) RenameGroup(dn string, rdn string) error {
+	return cl.RenameGroupContext(context.Background(), dn, rdn)
+}
+
+// Context-aware version of RenameGroup.
+func (cl *Client) RenameGroupContext(ctx context.Context, dn string, rdn string) error {
 	modReq := ldap.NewModifyDNRequest(dn, rdn, true, "")
 	modReq.Controls = []ldap.Control{}
 
-	return cl.ldap.ModifyDN(modReq)
+	return cl.modifyDNRequest(ctx, modReq)
 }
 
-func (cl *Client) getGroupMembers(dn string) ([]GroupMember, error) {
+func (cl *Client) getGroupMembers(ctx context.Context, dn string) ([]GroupMember, error) {
 	req := &ldap.SearchRequest{
 		BaseDN:       cl.Config.Users.SearchBase,
 		Scope:        ldap.ScopeWholeSubtree,
@@ -203,7 +241,7 @@ func (cl *Client) getGroupMembers(dn string) ([]GroupMember, error) {
 		Filter:       fmt.Sprintf(cl.Config.Groups.FilterMembersByDn, ldap.EscapeFilter(dn)),
 		Attributes:   []string{cl.Config.Users.IdAttribute},
 	}
-	entries, err := cl.searchEntries(req)
+	entries, err := cl.searchEntries(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +252,23 @@ func (cl *Client) getGroupMembers(dn string) ([]GroupMember, error) {
 			Id: e.GetAttributeValue(cl.Config.Groups.IdAttribute),
 		})
 	}
-	return result, nil
+
+	primary, err := cl.getPrimaryGroupMembers(ctx, dn)
+	if err != nil {
+		return nil, fmt.Errorf("can't get primary group members: %w", err)
+	}
+	return append(result, primary...), nil
+}
+
+// Returns DNs of members listed in the group 'member' attribute.
+func (g *Group) directMembersDn() []string {
+	var result []string
+	for _, m := range g.Members {
+		if !m.Primary {
+			result = append(result, m.DN)
+		}
+	}
+	return result
 }
 
 // Returns list of group members DNs.
@@ -237,9 +291,14 @@ func (g *Group) MembersId() []string {
 
 // Adds provided accounts IDs to provided group members. Returns number of addedd accounts.
 func (cl *Client) AddGroupMembers(groupId string, membersIds ...string) (int, error) {
-	group, err := cl.GetGroup(GetGroupArgs{Id: groupId})
+	return cl.AddGroupMembersContext(context.Background(), groupId, membersIds...)
+}
+
+// Context-aware version of AddGroupMembers.
+func (cl *Client) AddGroupMembersContext(ctx context.Context, groupId string, membersIds ...string) (int, error) {
+	group, err := cl.GetGroupContext(ctx, GetGroupArgs{Id: groupId})
 	if err != nil {
-		return 0, fmt.Errorf("can't get group: %s", err.Error())
+		return 0, fmt.Errorf("can't get group: %w", err)
 	}
 	if group == nil {
 		return 0, fmt.Errorf("group '%s' not found by ID", groupId)
@@ -253,9 +312,9 @@ func (cl *Client) AddGroupMembers(groupId string, membersIds ...string) (int, er
 		wg.Add(1)
 		go func(userId string, ch chan<- string, errCh chan<- error, wg *sync.WaitGroup) {
 			defer wg.Done()
-			user, err := cl.GetUser(GetUserArgs{Id: userId})
+			user, err := cl.GetUserContext(ctx, GetUserArgs{Id: userId})
 			if err != nil {
-				errCh <- fmt.Errorf("can't get account '%s': %s", userId, err.Error())
+				errCh <- fmt.Errorf("can't get account '%s': %w", userId, err)
 				return
 			}
 			if user == nil {
@@ -294,7 +353,7 @@ func (cl *Client) AddGroupMembers(groupId string, membersIds ...string) (int, er
 	cl.logger.Debugf("Adding new group members to '%s'; Old count: %d; New count: %d",
 		groupId, len(group.MembersId()), len(newMembers))
 
-	if err := cl.updateAttribute(group.DN, "member", newMembers); err != nil {
+	if err := cl.updateAttribute(ctx, group.DN, "member", newMembers); err != nil {
 		return 0, err
 	}
 
@@ -303,19 +362,24 @@ func (cl *Client) AddGroupMembers(groupId string, membersIds ...string) (int, er
 
 func popAddGroupMembers(g *Group, toAdd []string) []string {
 	if len(toAdd) == 0 {
-		return g.MembersDn()
+		return g.directMembersDn()
 	}
 	result := make([]string, 0, len(g.Members)+len(toAdd))
-	result = append(result, g.MembersDn()...)
+	result = append(result, g.directMembersDn()...)
 	result = append(result, toAdd...)
 	return result
 }
 
 // Deletes provided accounts IDs from provided group members. Returns number of deleted from group members.
 func (cl *Client) DeleteGroupMembers(groupId string, membersIds ...string) (int, error) {
-	group, err := cl.GetGroup(GetGroupArgs{Id: groupId})
+	return cl.DeleteGroupMembersContext(context.Background(), groupId, membersIds...)
+}
+
+// Context-aware version of DeleteGroupMembers.
+func (cl *Client) DeleteGroupMembersContext(ctx context.Context, groupId string, membersIds ...string) (int, error) {
+	group, err := cl.GetGroupContext(ctx, GetGroupArgs{Id: groupId})
 	if err != nil {
-		return 0, fmt.Errorf("can't get group: %s", err.Error())
+		return 0, fmt.Errorf("can't get group: %w", err)
 	}
 	if group == nil {
 		return 0, fmt.Errorf("group '%s' not found by ID", groupId)
@@ -329,9 +393,9 @@ func (cl *Client) DeleteGroupMembers(groupId string, membersIds ...string) (int,
 		wg.Add(1)
 		go func(userId string, ch chan<- string, errCh chan<- error, wg *sync.WaitGroup) {
 			defer wg.Done()
-			user, err := cl.GetUser(GetUserArgs{Id: userId})
+			user, err := cl.GetUserContext(ctx, GetUserArgs{Id: userId})
 			if err != nil {
-				errCh <- fmt.Errorf("can't get account '%s': %s", userId, err.Error())
+				errCh <- fmt.Errorf("can't get account '%s': %w", userId, err)
 				return
 			}
 			if user == nil {
@@ -344,6 +408,10 @@ func (cl *Client) DeleteGroupMembers(groupId string, membersIds ...string) (int,
 					userId, groupId)
 				return
 			}
+			if user.isPrimaryGroup(groupId) {
+				errCh <- fmt.Errorf("account '%s' can't be deleted from its primary group '%s'", userId, groupId)
+				return
+			}
 			ch <- user.DN
 		}(id, ch, errCh, wg)
 	}
@@ -370,7 +438,7 @@ func (cl *Client) DeleteGroupMembers(groupId string, membersIds ...string) (int,
 	cl.logger.Debugf("Deleting members from group '%s'; Old count: %d; New count: %d",
 		groupId, len(group.MembersId()), len(newMembers))
 
-	if err := cl.updateAttribute(group.DN, "member", newMembers); err != nil {
+	if err := cl.updateAttribute(ctx, group.DN, "member", newMembers); err != nil {
 		return 0, err
 	}
 
@@ -379,7 +447,7 @@ func (cl *Client) DeleteGroupMembers(groupId string, membersIds ...string) (int,
 
 func popDelGroupMembers(g *Group, toDel []string) []string {
 	result := []string{}
-	for _, memberDN := range g.MembersDn() {
+	for _, memberDN := range g.directMembersDn() {
 		if !slices.Contains(toDel, memberDN) {
 			result = append(result, memberDN)
 		}
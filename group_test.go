@@ -1,6 +1,7 @@
 package adc
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -171,6 +172,28 @@ func Test_AddGroupMembers(t *testing.T) {
 	})
 }
 
+func Test_Client_GroupContext(t *testing.T) {
+	cl := newMockClient(&Config{})
+	require.NoError(t, cl.Connect())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("GetGroup", func(t *testing.T) {
+		group, err := cl.GetGroupContext(ctx, GetGroupArgs{Id: "group1"})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, group)
+	})
+	t.Run("AddGroupMembers", func(t *testing.T) {
+		added, err := cl.AddGroupMembersContext(ctx, "group1", "userToAdd")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 0, added)
+	})
+	t.Run("DeleteGroup", func(t *testing.T) {
+		require.ErrorIs(t, cl.DeleteGroupContext(ctx, "OU=group1,DC=company,DC=com"), context.Canceled)
+	})
+}
+
 func Test_popAddGroupMembers(t *testing.T) {
 	group := &Group{
 		Members: []GroupMember{{DN: "oldone"}},
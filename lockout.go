@@ -0,0 +1,117 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Account lockout policy of the domain.
+type LockoutPolicy struct {
+	// Number of failed logon attempts that locks account. Zero means accounts are never locked.
+	Threshold int `json:"threshold"`
+	// Period after which locked account is unlocked automatically.
+	// Zero means account stays locked until administrator unlocks it.
+	Duration time.Duration `json:"duration"`
+	// Period after which failed logon attempts counter is reset.
+	ObservationWindow time.Duration `json:"observation_window"`
+}
+
+// Account lockout status.
+type LockoutStatus struct {
+	Locked bool `json:"locked"`
+	// Time when account was locked. Zero if account isn't locked.
+	LockedAt time.Time `json:"locked_at"`
+	// Time when account is unlocked automatically.
+	// Zero if account isn't locked or stays locked until administrator unlocks it.
+	UnlocksAt time.Time `json:"unlocks_at"`
+	// Number of failed logon attempts.
+	BadPasswordCount int `json:"bad_password_count"`
+	// Time of the last failed logon attempt.
+	LastBadPassword time.Time `json:"last_bad_password"`
+}
+
+// User attributes required to get lockout status.
+var lockoutAttributes = []string{"lockoutTime", "badPwdCount", "badPasswordTime"}
+
+// Returns account lockout status according to provided domain lockout policy.
+// lockoutTime, badPwdCount and badPasswordTime attributes must be requested.
+// Account with lockout time is reported as locked if policy isn't provided.
+func (u *User) LockoutStatus(policy *LockoutPolicy) LockoutStatus {
+	return u.lockoutStatus(policy, time.Now())
+}
+
+func (u *User) lockoutStatus(policy *LockoutPolicy, now time.Time) LockoutStatus {
+	status := LockoutStatus{
+		BadPasswordCount: int(intAttribute(u.Attributes, "badPwdCount")),
+		LastBadPassword:  u.GetTimeAttribute("badPasswordTime"),
+	}
+	lockedAt := u.GetTimeAttribute("lockoutTime")
+	if lockedAt.IsZero() {
+		return status
+	}
+	if policy == nil || policy.Duration == 0 {
+		status.Locked = true
+		status.LockedAt = lockedAt
+		return status
+	}
+	if unlocksAt := lockedAt.Add(policy.Duration); now.Before(unlocksAt) {
+		status.Locked = true
+		status.LockedAt = lockedAt
+		status.UnlocksAt = unlocksAt
+	}
+	return status
+}
+
+// Returns account lockout policy of the domain.
+func (cl *Client) GetLockoutPolicy() (*LockoutPolicy, error) {
+	return cl.GetLockoutPolicyContext(context.Background())
+}
+
+// Context-aware version of GetLockoutPolicy.
+func (cl *Client) GetLockoutPolicyContext(ctx context.Context) (*LockoutPolicy, error) {
+	entry, err := cl.readDomain(ctx, []string{"lockoutThreshold", "lockoutDuration", "lockOutObservationWindow"})
+	if err != nil {
+		return nil, err
+	}
+	threshold, _ := strconv.Atoi(entry.GetAttributeValue("lockoutThreshold"))
+	return &LockoutPolicy{
+		Threshold:         threshold,
+		Duration:          ParseInterval(entry.GetAttributeValue("lockoutDuration")),
+		ObservationWindow: ParseInterval(entry.GetAttributeValue("lockOutObservationWindow")),
+	}, nil
+}
+
+// Returns user account lockout status according to domain lockout policy.
+func (cl *Client) GetLockoutStatus(dn string) (*LockoutStatus, error) {
+	return cl.GetLockoutStatusContext(context.Background(), dn)
+}
+
+// Context-aware version of GetLockoutStatus.
+func (cl *Client) GetLockoutStatusContext(ctx context.Context, dn string) (*LockoutStatus, error) {
+	policy, err := cl.GetLockoutPolicyContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get lockout policy: %w", err)
+	}
+	entry, err := cl.readEntry(ctx, dn, lockoutAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("can't get account: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("account '%s' not found", dn)
+	}
+	user := &User{DN: entry.DN, Attributes: entryAttributes(entry)}
+	status := user.LockoutStatus(policy)
+	return &status, nil
+}
+
+// Unlocks locked user account.
+func (cl *Client) UnlockUser(dn string) error {
+	return cl.UnlockUserContext(context.Background(), dn)
+}
+
+// Context-aware version of UnlockUser.
+func (cl *Client) UnlockUserContext(ctx context.Context, dn string) error {
+	return cl.updateAttribute(ctx, dn, "lockoutTime", []string{"0"})
+}
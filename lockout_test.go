@@ -0,0 +1,101 @@
+package adc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_User_LockoutStatus(t *testing.T) {
+	lockedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	badPasswordAt := lockedAt.Add(-time.Second)
+	policy := &LockoutPolicy{Threshold: 5, Duration: 30 * time.Minute}
+
+	user := &User{Attributes: map[string]interface{}{
+		"lockoutTime":     strconv.FormatInt(TimeToFileTime(lockedAt), 10),
+		"badPwdCount":     "5",
+		"badPasswordTime": strconv.FormatInt(TimeToFileTime(badPasswordAt), 10),
+	}}
+
+	t.Run("Locked", func(t *testing.T) {
+		status := user.lockoutStatus(policy, lockedAt.Add(10*time.Minute))
+		require.Equal(t, LockoutStatus{
+			Locked:           true,
+			LockedAt:         lockedAt,
+			UnlocksAt:        lockedAt.Add(30 * time.Minute),
+			BadPasswordCount: 5,
+			LastBadPassword:  badPasswordAt,
+		}, status)
+	})
+	t.Run("AutoUnlocked", func(t *testing.T) {
+		status := user.lockoutStatus(policy, lockedAt.Add(time.Hour))
+		require.False(t, status.Locked)
+		require.True(t, status.LockedAt.IsZero())
+		require.True(t, status.UnlocksAt.IsZero())
+		require.Equal(t, 5, status.BadPasswordCount)
+	})
+	t.Run("UntilAdminUnlocks", func(t *testing.T) {
+		status := user.lockoutStatus(&LockoutPolicy{Threshold: 5}, lockedAt.Add(24*time.Hour))
+		require.True(t, status.Locked)
+		require.True(t, status.UnlocksAt.IsZero())
+
+		require.True(t, user.LockoutStatus(nil).Locked)
+	})
+	t.Run("NotLocked", func(t *testing.T) {
+		status := (&User{Attributes: map[string]interface{}{"lockoutTime": "0", "badPwdCount": "1"}}).LockoutStatus(policy)
+		require.False(t, status.Locked)
+		require.Equal(t, 1, status.BadPasswordCount)
+		require.False(t, (&User{}).LockoutStatus(policy).Locked)
+	})
+}
+
+func Test_ParseInterval(t *testing.T) {
+	require.Equal(t, 30*time.Minute, ParseInterval("-18000000000"))
+	require.Equal(t, 42*24*time.Hour, ParseInterval("-36288000000000"))
+	require.Equal(t, time.Duration(0), ParseInterval("-9223372036854775808"))
+	require.Equal(t, time.Duration(0), ParseInterval("0"))
+	require.Equal(t, time.Duration(0), ParseInterval(""))
+}
+
+func Test_Client_Lockout(t *testing.T) {
+	user1DN := "OU=user1,DC=company,DC=com"
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	policy, err := cl.GetLockoutPolicy()
+	require.NoError(t, err)
+	require.Equal(t, &LockoutPolicy{Threshold: 5, Duration: 30 * time.Minute, ObservationWindow: 15 * time.Minute}, policy)
+
+	status, err := cl.GetLockoutStatus(user1DN)
+	require.NoError(t, err)
+	require.False(t, status.Locked)
+
+	lockedAt := time.Now().Add(-5 * time.Minute).Truncate(time.Second)
+	require.NoError(t, cl.UpdateUser(user1DN, []ldap.Attribute{
+		{Type: "lockoutTime", Vals: []string{strconv.FormatInt(TimeToFileTime(lockedAt), 10)}},
+		{Type: "badPwdCount", Vals: []string{"5"}},
+	}))
+	status, err = cl.GetLockoutStatus(user1DN)
+	require.NoError(t, err)
+	require.True(t, status.Locked)
+	require.Equal(t, 5, status.BadPasswordCount)
+	require.True(t, lockedAt.Add(30*time.Minute).Equal(status.UnlocksAt))
+
+	require.NoError(t, cl.UnlockUser(user1DN))
+	status, err = cl.GetLockoutStatus(user1DN)
+	require.NoError(t, err)
+	require.False(t, status.Locked)
+
+	_, err = cl.GetLockoutStatus("OU=unknown,DC=company,DC=com")
+	require.ErrorContains(t, err, "not found")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, cl.UnlockUserContext(ctx, user1DN), context.Canceled)
+	_, err = cl.GetLockoutPolicyContext(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
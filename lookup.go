@@ -0,0 +1,31 @@
+package adc
+
+import (
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Returns filter to find entry matching base filter by the first provided identifier.
+// Binary GUID and SID values are escaped byte by byte. Returns empty string if no identifier provided.
+func lookupFilter(base, guid, sid, upn, mail string) (string, error) {
+	switch {
+	case guid != "":
+		b, err := EncodeGUID(guid)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(&%s(objectGUID=%s))", base, escapeFilterBytes(b)), nil
+	case sid != "":
+		b, err := EncodeSID(sid)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(&%s(objectSid=%s))", base, escapeFilterBytes(b)), nil
+	case upn != "":
+		return fmt.Sprintf("(&%s(userPrincipalName=%s))", base, ldap.EscapeFilter(upn)), nil
+	case mail != "":
+		return fmt.Sprintf("(&%s(mail=%s))", base, ldap.EscapeFilter(mail)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,76 @@
+package adc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lookupFilter(t *testing.T) {
+	filter, err := lookupFilter("(&(objectClass=person))", "04030201-0605-0807-090a-0b0c0d0e0f10", "S-1-5-32", "", "")
+	require.NoError(t, err)
+	require.Equal(t, `(&(&(objectClass=person))(objectGUID=\01\02\03\04\05\06\07\08\09\0a\0b\0c\0d\0e\0f\10))`, filter, "GUID has priority")
+
+	filter, err = lookupFilter("(&(objectClass=group))", "", "S-1-5-32-544", "", "")
+	require.NoError(t, err)
+	require.Equal(t, `(&(&(objectClass=group))(objectSid=\01\02\00\00\00\00\00\05\20\00\00\00\20\02\00\00))`, filter)
+
+	filter, err = lookupFilter("(&(objectClass=person))", "", "", "j*smith@company.com", "")
+	require.NoError(t, err)
+	require.Equal(t, `(&(&(objectClass=person))(userPrincipalName=j\2asmith@company.com))`, filter)
+
+	filter, err = lookupFilter("(&(objectClass=person))", "", "", "", "john@company.com")
+	require.NoError(t, err)
+	require.Equal(t, `(&(&(objectClass=person))(mail=john@company.com))`, filter)
+
+	filter, err = lookupFilter("(&(objectClass=person))", "", "", "", "")
+	require.NoError(t, err)
+	require.Empty(t, filter)
+
+	_, err = lookupFilter("(&(objectClass=person))", "invalid", "", "", "")
+	require.Error(t, err)
+	_, err = lookupFilter("(&(objectClass=person))", "", "invalid", "", "")
+	require.Error(t, err)
+}
+
+func Test_Client_Lookup(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	t.Run("User", func(t *testing.T) {
+		for name, args := range map[string]GetUserArgs{
+			"GUID": {GUID: "04030201-0605-0807-090a-0b0c0d0e0f10"},
+			"SID":  {SID: "S-1-5-21-1004336348-1177238915-682003330-1105"},
+			"UPN":  {UPN: "user1@company.com"},
+			"Mail": {Mail: "user1@company.com"},
+		} {
+			args.SkipGroupsSearch = true
+			user, err := cl.GetUser(args)
+			require.NoError(t, err, name)
+			require.NotNil(t, user, name)
+			require.Equal(t, "user1", user.Id, name)
+		}
+
+		user, err := cl.GetUser(GetUserArgs{UPN: "unknown@company.com"})
+		require.NoError(t, err)
+		require.Nil(t, user)
+
+		_, err = cl.GetUser(GetUserArgs{GUID: "invalid"})
+		require.ErrorContains(t, err, "invalid GUID")
+	})
+	t.Run("Group", func(t *testing.T) {
+		group, err := cl.GetGroup(GetGroupArgs{SID: "S-1-5-21-1004336348-1177238915-682003330-513", SkipMembersSearch: true})
+		require.NoError(t, err)
+		require.NotNil(t, group)
+		require.Equal(t, "CN=Domain Users,CN=Users,DC=company,DC=com", group.DN)
+
+		_, err = cl.GetGroup(GetGroupArgs{SID: "invalid"})
+		require.ErrorContains(t, err, "invalid SID")
+	})
+	t.Run("Validate", func(t *testing.T) {
+		require.NoError(t, GetUserArgs{GUID: "guid"}.Validate())
+		require.NoError(t, GetUserArgs{UPN: "upn"}.Validate())
+		require.NoError(t, GetGroupArgs{Mail: "mail"}.Validate())
+		require.Error(t, GetGroupArgs{}.Validate())
+	})
+}
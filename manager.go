@@ -0,0 +1,200 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Node of organization tree: user and users reporting to it.
+type OrgNode struct {
+	User    User      `json:"user"`
+	Reports []OrgNode `json:"reports"`
+}
+
+// Returns manager of the user with provided ID. Returns nil if user has no manager or it isn't a user.
+func (cl *Client) GetManager(userId string) (*User, error) {
+	return cl.GetManagerContext(context.Background(), userId)
+}
+
+// Context-aware version of GetManager.
+func (cl *Client) GetManagerContext(ctx context.Context, userId string) (*User, error) {
+	dn, err := cl.getUserDN(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	managers, err := cl.readLinks(ctx, dn, "manager")
+	if err != nil || len(managers) == 0 {
+		return nil, err
+	}
+	return cl.getLinkedUser(ctx, managers[0])
+}
+
+// Returns users reporting directly to the user with provided ID, resolved by 'directReports' back-link.
+func (cl *Client) GetDirectReports(userId string) ([]User, error) {
+	return cl.GetDirectReportsContext(context.Background(), userId)
+}
+
+// Context-aware version of GetDirectReports.
+func (cl *Client) GetDirectReportsContext(ctx context.Context, userId string) ([]User, error) {
+	dn, err := cl.getUserDN(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return cl.getDirectReports(ctx, dn)
+}
+
+// Returns managers chain of the user with provided ID, from direct manager up to the top one.
+// Chain stops before a manager that is already in it, so circular references don't cause infinite loop.
+// Chain also stops at a manager that isn't a user.
+func (cl *Client) GetReportingChain(userId string) ([]User, error) {
+	return cl.GetReportingChainContext(context.Background(), userId)
+}
+
+// Context-aware version of GetReportingChain.
+func (cl *Client) GetReportingChainContext(ctx context.Context, userId string) ([]User, error) {
+	dn, err := cl.getUserDN(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{strings.ToLower(dn): true}
+	var result []User
+	for {
+		managers, err := cl.readLinks(ctx, dn, "manager")
+		if err != nil {
+			return nil, err
+		}
+		if len(managers) == 0 {
+			return result, nil
+		}
+		dn = managers[0]
+		if visited[strings.ToLower(dn)] {
+			cl.logger.Debugf("Reporting chain of '%s' has a cycle at '%s'", userId, dn)
+			return result, nil
+		}
+		visited[strings.ToLower(dn)] = true
+
+		manager, err := cl.getLinkedUser(ctx, dn)
+		if err != nil || manager == nil {
+			return result, err
+		}
+		result = append(result, *manager)
+	}
+}
+
+// Returns organization tree under the user with provided ID, walking direct reports recursively.
+// Zero maxDepth means the whole subtree. Users already in the tree are skipped, so circular references are ignored.
+func (cl *Client) GetOrgTree(managerId string, maxDepth int) (*OrgNode, error) {
+	return cl.GetOrgTreeContext(context.Background(), managerId, maxDepth)
+}
+
+// Context-aware version of GetOrgTree.
+func (cl *Client) GetOrgTreeContext(ctx context.Context, managerId string, maxDepth int) (*OrgNode, error) {
+	manager, err := cl.GetUserContext(ctx, GetUserArgs{Id: managerId, SkipGroupsSearch: true})
+	if err != nil {
+		return nil, err
+	}
+	if manager == nil {
+		return nil, fmt.Errorf("user '%s' not found by ID", managerId)
+	}
+
+	root := &OrgNode{User: *manager}
+	visited := map[string]bool{strings.ToLower(manager.DN): true}
+	if err := cl.walkOrgTree(ctx, root, 1, maxDepth, visited); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (cl *Client) walkOrgTree(ctx context.Context, node *OrgNode, depth, maxDepth int, visited map[string]bool) error {
+	if maxDepth > 0 && depth > maxDepth {
+		return nil
+	}
+	reports, err := cl.getDirectReports(ctx, node.User.DN)
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		if visited[strings.ToLower(r.DN)] {
+			cl.logger.Debugf("Org tree has a cycle at '%s'", r.DN)
+			continue
+		}
+		visited[strings.ToLower(r.DN)] = true
+		node.Reports = append(node.Reports, OrgNode{User: r})
+	}
+	for i := range node.Reports {
+		if err := cl.walkOrgTree(ctx, &node.Reports[i], depth+1, maxDepth, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sets manager of the user. Empty manager DN clears it.
+func (cl *Client) SetManager(userDN, managerDN string) error {
+	return cl.SetManagerContext(context.Background(), userDN, managerDN)
+}
+
+// Context-aware version of SetManager.
+func (cl *Client) SetManagerContext(ctx context.Context, userDN, managerDN string) error {
+	var values []string
+	if managerDN != "" {
+		values = []string{managerDN}
+	}
+	return cl.updateAttribute(ctx, userDN, "manager", values)
+}
+
+func (cl *Client) getUserDN(ctx context.Context, userId string) (string, error) {
+	user, err := cl.GetUserContext(ctx, GetUserArgs{Id: userId, Attributes: []string{cl.Config.Users.IdAttribute}, SkipGroupsSearch: true})
+	if err != nil {
+		return "", fmt.Errorf("can't get user: %w", err)
+	}
+	if user == nil {
+		return "", fmt.Errorf("user '%s' not found by ID", userId)
+	}
+	return user.DN, nil
+}
+
+func (cl *Client) getDirectReports(ctx context.Context, dn string) ([]User, error) {
+	reports, err := cl.readLinks(ctx, dn, "directReports")
+	if err != nil {
+		return nil, err
+	}
+	var result []User
+	for _, r := range reports {
+		user, err := cl.getLinkedUser(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			result = append(result, *user)
+		}
+	}
+	return result, nil
+}
+
+// Returns values of DN-valued attribute of the entry, e.g. manager.
+func (cl *Client) readLinks(ctx context.Context, dn, attribute string) ([]string, error) {
+	entry, err := cl.readEntry(ctx, dn, []string{attribute})
+	if err != nil {
+		return nil, fmt.Errorf("can't get '%s' of '%s': %w", attribute, dn, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("account '%s' not found", dn)
+	}
+	return entry.GetAttributeValues(attribute), nil
+}
+
+// Returns user referenced by DN-valued attribute with configured attributes.
+// Returns nil if referenced entry isn't a user in users search base, e.g. it's a contact.
+func (cl *Client) getLinkedUser(ctx context.Context, dn string) (*User, error) {
+	user, err := cl.GetUserContext(ctx, GetUserArgs{Dn: dn, SkipGroupsSearch: true})
+	if err != nil {
+		return nil, fmt.Errorf("can't get user '%s': %w", dn, err)
+	}
+	if user == nil {
+		cl.logger.Debugf("Linked user '%s' wasn't found", dn)
+	}
+	return user, nil
+}
@@ -0,0 +1,109 @@
+package adc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func usersIds(users []User) []string {
+	var result []string
+	for _, u := range users {
+		result = append(result, u.Id)
+	}
+	return result
+}
+
+func Test_Client_GetManager(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	manager, err := cl.GetManager("dev1")
+	require.NoError(t, err)
+	require.NotNil(t, manager)
+	require.Equal(t, "cto", manager.Id)
+	require.Equal(t, mockOrgDN("cto"), manager.DN)
+
+	manager, err = cl.GetManager("ceo")
+	require.NoError(t, err)
+	require.Nil(t, manager, "Top manager")
+
+	_, err = cl.GetManager("userFake")
+	require.ErrorContains(t, err, "not found")
+}
+
+func Test_Client_GetDirectReports(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	reports, err := cl.GetDirectReports("cto")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev1", "dev2"}, usersIds(reports))
+
+	reports, err = cl.GetDirectReports("dev1")
+	require.NoError(t, err)
+	require.Empty(t, reports)
+}
+
+func Test_Client_GetReportingChain(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	chain, err := cl.GetReportingChain("dev2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cto", "ceo"}, usersIds(chain))
+
+	chain, err = cl.GetReportingChain("cycleA")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cycleB"}, usersIds(chain), "Chain stops at the cycle")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cl.GetReportingChainContext(ctx, "dev2")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Client_GetOrgTree(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	tree, err := cl.GetOrgTree("ceo", 0)
+	require.NoError(t, err)
+	require.Equal(t, "ceo", tree.User.Id)
+	require.Len(t, tree.Reports, 1)
+	require.Equal(t, "cto", tree.Reports[0].User.Id)
+	require.Len(t, tree.Reports[0].Reports, 2)
+	require.Equal(t, "dev1", tree.Reports[0].Reports[0].User.Id)
+	require.Equal(t, "dev2", tree.Reports[0].Reports[1].User.Id)
+	require.Empty(t, tree.Reports[0].Reports[0].Reports)
+
+	tree, err = cl.GetOrgTree("ceo", 1)
+	require.NoError(t, err)
+	require.Len(t, tree.Reports, 1)
+	require.Empty(t, tree.Reports[0].Reports, "Depth is limited")
+
+	tree, err = cl.GetOrgTree("cycleA", 0)
+	require.NoError(t, err)
+	require.Len(t, tree.Reports, 1)
+	require.Equal(t, "cycleB", tree.Reports[0].User.Id)
+	require.Empty(t, tree.Reports[0].Reports, "Cycle is skipped")
+
+	_, err = cl.GetOrgTree("userFake", 0)
+	require.ErrorContains(t, err, "not found")
+}
+
+func Test_Client_SetManager(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	require.NoError(t, cl.SetManager(mockOrgDN("dev2"), mockOrgDN("ceo")))
+	manager, err := cl.GetManager("dev2")
+	require.NoError(t, err)
+	require.Equal(t, "ceo", manager.Id)
+
+	require.NoError(t, cl.SetManager(mockOrgDN("dev2"), ""))
+	manager, err = cl.GetManager("dev2")
+	require.NoError(t, err)
+	require.Nil(t, manager)
+}
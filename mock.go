@@ -3,8 +3,13 @@ package adc
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-ldap/ldap/v3"
@@ -13,11 +18,32 @@ import (
 // Entry attribute name, that helps match entry to provided request.
 const mockFiltersAttribute = "filtersToFind"
 
+// Mock filter value suffix that makes it match any filter with the same prefix.
+// Used for filters with time dependent values.
+const mockFilterPrefixSuffix = "..."
+
+func mockFilterMatches(mockFilter, filter string) bool {
+	if prefix, ok := strings.CutSuffix(mockFilter, mockFilterPrefixSuffix); ok {
+		return strings.HasPrefix(filter, prefix)
+	}
+	return mockFilter == filter
+}
+
 // Mock client. Implements ldap client interface.
 var _ ldap.Client = (*mockClient)(nil)
 
 type mockClient struct {
 	entries map[string]*ldap.Entry
+	closed  atomic.Bool
+	tls     bool
+	// Makes operations fail with network error and close connection.
+	broken bool
+	// Server host for GSSAPI service principal check.
+	host string
+	// DN of bound account.
+	boundDN string
+	// Encoded passwords by account DN.
+	passwords map[string]string
 }
 
 // Extended implements ldap.Client.
@@ -36,14 +62,57 @@ func newMockClient(cfg *Config, opts ...Option) *Client {
 func mockConnection() (*mockClient, error) {
 	cl := &mockClient{
 		entries: map[string]*ldap.Entry{
+			"rootDSE": {
+				DN: "",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "defaultNamingContext", Values: []string{"DC=company,DC=com"}},
+					{Name: mockFiltersAttribute, Values: []string{"(objectClass=*)"}},
+				},
+			},
+			"domain": {
+				DN: "DC=company,DC=com",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "lockoutThreshold", Values: []string{"5"}},
+					{Name: "lockoutDuration", Values: []string{"-18000000000"}},
+					{Name: "lockOutObservationWindow", Values: []string{"-9000000000"}},
+					{Name: "minPwdLength", Values: []string{"8"}},
+					{Name: "pwdProperties", Values: []string{"1"}},
+					{Name: "pwdHistoryLength", Values: []string{"24"}},
+					{Name: "maxPwdAge", Values: []string{"-36288000000000"}},
+					{Name: "minPwdAge", Values: []string{"-864000000000"}},
+				},
+			},
+			"pso": {
+				DN: "CN=Admins,CN=Password Settings Container,CN=System,DC=company,DC=com",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "msDS-MinimumPasswordLength", Values: []string{"15"}},
+					{Name: "msDS-PasswordComplexityEnabled", Values: []string{"TRUE"}},
+					{Name: "msDS-PasswordHistoryLength", Values: []string{"10"}},
+					{Name: "msDS-MaximumPasswordAge", Values: []string{"-77760000000000"}},
+					{Name: "msDS-MinimumPasswordAge", Values: []string{"0"}},
+				},
+			},
 			"user1": {
 				DN: "OU=user1,DC=company,DC=com",
 				Attributes: []*ldap.EntryAttribute{
 					{Name: "sAMAccountName", Values: []string{"user1"}},
+					{Name: "userPrincipalName", Values: []string{"user1@company.com"}},
+					{Name: "mail", Values: []string{"user1@company.com"}},
+					mockBinaryAttribute("objectGUID", mockUser1GUID),
+					mockBinaryAttribute("objectSid", mockSID(mockDomainSubAuthorities, 1105)),
+					{Name: "userAccountControl", Values: []string{"66048"}},
+					{Name: "pwdLastSet", Values: []string{"132539328000000000"}},
+					{Name: "accountExpires", Values: []string{"9223372036854775807"}},
+					{Name: "whenCreated", Values: []string{"20240115103000.0Z"}},
 					{Name: mockFiltersAttribute, Values: []string{
 						"(&(objectClass=person)(sAMAccountName=user1))",
 						"(&(objectClass=person)(distinguishedName=OU=user1,DC=company,DC=com))",
 						"(&(objectCategory=person)(memberOf=OU=group1,DC=company,DC=com))",
+						"(&(&(objectClass=person))(accountExpires>=...",
+						"(&(&(objectClass=person))(objectGUID=" + escapeFilterBytes(mockUser1GUID) + "))",
+						"(&(&(objectClass=person))(objectSid=" + escapeFilterBytes(mockSID(mockDomainSubAuthorities, 1105)) + "))",
+						"(&(&(objectClass=person))(userPrincipalName=user1@company.com))",
+						"(&(&(objectClass=person))(mail=user1@company.com))",
 						"customFilterToSearchUser",
 					}},
 				},
@@ -52,10 +121,16 @@ func mockConnection() (*mockClient, error) {
 				DN: "OU=user2,DC=company,DC=com",
 				Attributes: []*ldap.EntryAttribute{
 					{Name: "sAMAccountName", Values: []string{"user2"}},
+					{Name: "displayName", Values: []string{"Second User-Admin"}},
+					mockBinaryAttribute("objectSid", mockSID(mockDomainSubAuthorities, 1106)),
+					{Name: "primaryGroupID", Values: []string{"513"}},
+					{Name: "msDS-ResultantPSO", Values: []string{"CN=Admins,CN=Password Settings Container,CN=System,DC=company,DC=com"}},
 					{Name: mockFiltersAttribute, Values: []string{
 						"(&(objectClass=person)(sAMAccountName=user2))",
 						"(&(objectClass=person)(distinguishedName=OU=user2,DC=company,DC=com))",
+						"(&(&(objectClass=person))(!(userAccountControl:1.2.840.113556.1.4.803:=2))(!(userAccountControl:1.2.840.113556.1.4.803:=65536))(pwdLastSet>=...",
 						"(&(objectCategory=person)(memberOf=OU=group2,DC=company,DC=com))",
+						"(&(&(objectClass=person))(primaryGroupID=513))",
 					}},
 				},
 			},
@@ -78,6 +153,7 @@ func mockConnection() (*mockClient, error) {
 						"(&(objectClass=group)(sAMAccountName=group1))",
 						"(&(objectClass=group)(distinguishedName=OU=group1,DC=company,DC=com))",
 						"(&(objectClass=group)(member=OU=user1,DC=company,DC=com))",
+						"(&(objectClass=group)(member:1.2.840.113556.1.4.1941:=OU=user1,DC=company,DC=com))",
 						"customFilterToSearchGroup",
 					}},
 				},
@@ -93,6 +169,28 @@ func mockConnection() (*mockClient, error) {
 					}},
 				},
 			},
+			"group3": {
+				DN: "OU=group3,DC=company,DC=com",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "sAMAccountName", Values: []string{"group3"}},
+					{Name: "member", Values: []string{"OU=group1,DC=company,DC=com"}},
+					{Name: mockFiltersAttribute, Values: []string{
+						"(&(objectClass=group)(sAMAccountName=group3))",
+						"(&(objectClass=group)(member:1.2.840.113556.1.4.1941:=OU=user1,DC=company,DC=com))",
+					}},
+				},
+			},
+			"domainUsers": {
+				DN: "CN=Domain Users,CN=Users,DC=company,DC=com",
+				Attributes: []*ldap.EntryAttribute{
+					{Name: "sAMAccountName", Values: []string{"Domain Users"}},
+					mockBinaryAttribute("objectSid", mockSID(mockDomainSubAuthorities, 513)),
+					{Name: mockFiltersAttribute, Values: []string{
+						"(&(objectClass=group)(sAMAccountName=Domain Users))",
+						"(&(&(objectClass=group))(objectSid=" + escapeFilterBytes(mockSID(mockDomainSubAuthorities, 513)) + "))",
+					}},
+				},
+			},
 			"entryForErr": {
 				DN: "OU=entryForErr,DC=company,DC=com",
 				Attributes: []*ldap.EntryAttribute{
@@ -152,11 +250,93 @@ func mockConnection() (*mockClient, error) {
 				},
 			},
 		},
+		passwords: map[string]string{
+			user1MockBind.DN: encodePassword(user1MockBind.Password),
+		},
+	}
+
+	// Org chart: ceo <- cto <- dev1, dev2; cycleA and cycleB are managers of each other.
+	for _, e := range []*ldap.Entry{
+		mockOrgPerson("ceo", "", "cto"),
+		mockOrgPerson("cto", "ceo", "dev1", "dev2"),
+		mockOrgPerson("dev1", "cto"),
+		mockOrgPerson("dev2", "cto"),
+		mockOrgPerson("cycleA", "cycleB", "cycleB"),
+		mockOrgPerson("cycleB", "cycleA", "cycleA"),
+	} {
+		cl.entries[e.GetAttributeValue("sAMAccountName")] = e
 	}
 
 	return cl, nil
 }
 
+func mockOrgDN(id string) string {
+	return "CN=" + id + ",OU=org,DC=company,DC=com"
+}
+
+// Returns person entry with provided manager and direct reports IDs.
+func mockOrgPerson(id, manager string, reports ...string) *ldap.Entry {
+	entry := &ldap.Entry{
+		DN: mockOrgDN(id),
+		Attributes: []*ldap.EntryAttribute{
+			{Name: "sAMAccountName", Values: []string{id}},
+			{Name: "primaryGroupID", Values: []string{"513"}},
+			{Name: mockFiltersAttribute, Values: []string{
+				fmt.Sprintf("(&(objectClass=person)(sAMAccountName=%s))", id),
+				fmt.Sprintf("(&(objectClass=person)(distinguishedName=%s))", ldap.EscapeFilter(mockOrgDN(id))),
+				"(&(&(objectClass=person))(primaryGroupID=513))",
+			}},
+		},
+	}
+	if manager != "" {
+		entry.Attributes = append(entry.Attributes, &ldap.EntryAttribute{Name: "manager", Values: []string{mockOrgDN(manager)}})
+	}
+	if len(reports) > 0 {
+		dns := make([]string, 0, len(reports))
+		for _, r := range reports {
+			dns = append(dns, mockOrgDN(r))
+		}
+		entry.Attributes = append(entry.Attributes, &ldap.EntryAttribute{Name: "directReports", Values: dns})
+	}
+	return entry
+}
+
+var (
+	// Sub-authorities of mock domain SID S-1-5-21-1004336348-1177238915-682003330.
+	mockDomainSubAuthorities = []uint32{21, 1004336348, 1177238915, 682003330}
+	// Binary form of GUID 04030201-0605-0807-090a-0b0c0d0e0f10.
+	mockUser1GUID = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+)
+
+// Returns binary SID with NT authority and provided sub-authorities followed by RID.
+func mockSID(subAuthorities []uint32, rid uint32) []byte {
+	sid := []byte{1, byte(len(subAuthorities) + 1), 0, 0, 0, 0, 0, 5}
+	for _, v := range append(slices.Clone(subAuthorities), rid) {
+		sid = binary.LittleEndian.AppendUint32(sid, v)
+	}
+	return sid
+}
+
+func mockBinaryAttribute(name string, value []byte) *ldap.EntryAttribute {
+	return &ldap.EntryAttribute{Name: name, Values: []string{string(value)}, ByteValues: [][]byte{value}}
+}
+
+// Server host that makes mock dial fail.
+const mockDownHost = "down"
+
+// Mimics dial to provided server URL.
+func mockDial(serverURL string) (*mockClient, error) {
+	if urlHostname(serverURL) == mockDownHost {
+		return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("server is down"))
+	}
+	conn, err := mockConnection()
+	if err == nil {
+		conn.tls = strings.HasPrefix(serverURL, "ldaps://")
+		conn.host = urlHostname(serverURL)
+	}
+	return conn, err
+}
+
 func (cl *mockClient) getEntryByDn(dn string) *ldap.Entry {
 	for _, entry := range cl.entries {
 		if entry.DN == dn {
@@ -170,7 +350,7 @@ func (cl *mockClient) getEntriesByFilter(filter string) ([]*ldap.Entry, error) {
 	var result []*ldap.Entry
 	for id, entry := range cl.entries {
 		filters := entry.GetAttributeValues(mockFiltersAttribute)
-		if slices.Contains(filters, filter) {
+		if slices.ContainsFunc(filters, func(f string) bool { return mockFilterMatches(f, filter) }) {
 			if id == "entryForErr" {
 				return nil, errors.New("error for tests")
 			}
@@ -180,35 +360,79 @@ func (cl *mockClient) getEntriesByFilter(filter string) ([]*ldap.Entry, error) {
 			result = append(result, entry)
 		}
 	}
+	// Stable order is required for paging.
+	slices.SortFunc(result, func(a, b *ldap.Entry) int { return strings.Compare(a.DN, b.DN) })
 	return result, nil
 }
 
+// Maximum number of entries returned by mock search or its page. Mimics AD MaxPageSize policy.
+const mockSizeLimit = 3
+
+// Mimics AD search limits: search without paging control fails if more than limit entries match,
+// otherwise entries are returned by pages not larger than the limit with offset of the next page as cookie.
+func mockSearchPage(req *ldap.SearchRequest, entries []*ldap.Entry) (*ldap.SearchResult, error) {
+	paging, _ := ldap.FindControl(req.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	if paging == nil {
+		if len(entries) > mockSizeLimit {
+			return nil, ldap.NewError(ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+		}
+		return &ldap.SearchResult{Entries: entries}, nil
+	}
+
+	offset, _ := strconv.Atoi(string(paging.Cookie))
+	offset = min(offset, len(entries))
+	end := min(offset+min(int(paging.PagingSize), mockSizeLimit), len(entries))
+	var cookie []byte
+	if end < len(entries) {
+		cookie = []byte(strconv.Itoa(end))
+	}
+	return &ldap.SearchResult{
+		Entries:  entries[offset:end],
+		Controls: []ldap.Control{&ldap.ControlPaging{PagingSize: paging.PagingSize, Cookie: cookie}},
+	}, nil
+}
+
 func (cl *mockClient) Start() {}
 
-func (cl *mockClient) StartTLS(*tls.Config) error { return nil }
+// Server name that makes mock StartTLS fail.
+const mockNoStartTLSHost = "notls"
 
-func (cl *mockClient) Close() error { return nil }
+func (cl *mockClient) StartTLS(cfg *tls.Config) error {
+	if cfg.ServerName == mockNoStartTLSHost {
+		return ldap.NewError(ldap.LDAPResultProtocolError, errors.New("StartTLS isn't supported"))
+	}
+	cl.tls = true
+	return nil
+}
+
+func (cl *mockClient) Close() error {
+	cl.closed.Store(true)
+	return nil
+}
 
 func (cl *mockClient) GetLastError() error { return nil }
 
-func (cl *mockClient) IsClosing() bool { return false }
+func (cl *mockClient) IsClosing() bool { return cl.closed.Load() }
 
 func (cl *mockClient) SetTimeout(time.Duration) {}
 
 func (cl *mockClient) TLSConnectionState() (tls.ConnectionState, bool) {
-	return tls.ConnectionState{}, true
+	return tls.ConnectionState{}, cl.tls
 }
 
 var (
 	validMockBind     = &BindAccount{DN: "validUser", Password: "validPass"}
 	reconnectMockBind = &BindAccount{DN: "OU=userToReconnect,DC=company,DC=com", Password: "validPass"}
+	user1MockBind     = &BindAccount{DN: "OU=user1,DC=company,DC=com", Password: "user1Pass"}
 )
 
 func (cl *mockClient) Bind(username, password string) error {
-	if username == validMockBind.DN && password == validMockBind.Password {
+	if (username == validMockBind.DN && password == validMockBind.Password) ||
+		(username == user1MockBind.DN && password == user1MockBind.Password) {
+		cl.boundDN = username
 		return nil
 	}
-	return errors.New("unauthorised")
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("unauthorised"))
 }
 
 func (cl *mockClient) UnauthenticatedBind(username string) error {
@@ -219,7 +443,51 @@ func (cl *mockClient) SimpleBind(*ldap.SimpleBindRequest) (*ldap.SimpleBindResul
 	return nil, nil
 }
 
-func (cl *mockClient) ExternalBind() error { return nil }
+func (cl *mockClient) ExternalBind() error {
+	if !cl.tls {
+		return ldap.NewError(ldap.LDAPResultInappropriateAuthentication, errors.New("no client certificate"))
+	}
+	return nil
+}
+
+// Service ticket accepted by mock server GSSAPI bind.
+const mockGSSAPITicket = "mockTicket"
+
+// Mimics GSSAPI token exchange. Server accepts mock ticket for its 'ldap/<host>' principal
+// and offers no security layer with maximum buffer size.
+func (cl *mockClient) GSSAPIBindRequest(client ldap.GSSAPIClient, req *ldap.GSSAPIBindRequest) error {
+	defer client.DeleteSecContext() //nolint:errcheck
+
+	token, needContinue, err := client.InitSecContext(req.ServicePrincipalName, nil)
+	if err != nil {
+		return err
+	}
+	if needContinue || req.ServicePrincipalName != "ldap/"+cl.host || string(token) != mockGSSAPITicket {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid ticket"))
+	}
+	_, err = client.NegotiateSaslAuth([]byte{0x01, 0xFF, 0xFF, 0xFF}, req.AuthZID)
+	return err
+}
+
+// Valid NTLM account of mock server.
+const (
+	mockNTLMDomain = "COMPANY"
+	mockNTHash     = "b2a5d0b1a4d8e9c0f3c1b9e3c9d7a2f1"
+)
+
+func (cl *mockClient) NTLMBind(domain, username, password string) error {
+	if domain == mockNTLMDomain && username == validMockBind.DN && password == validMockBind.Password {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("unauthorised"))
+}
+
+func (cl *mockClient) NTLMBindWithHash(domain, username, hash string) error {
+	if domain == mockNTLMDomain && username == validMockBind.DN && hash == mockNTHash {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("unauthorised"))
+}
 
 func (cl *mockClient) NTLMUnauthenticatedBind(domain, username string) error {
 	return nil
@@ -232,6 +500,9 @@ func (cl *mockClient) Add(*ldap.AddRequest) error { return nil }
 func (cl *mockClient) Del(*ldap.DelRequest) error { return nil }
 
 func (cl *mockClient) Modify(req *ldap.ModifyRequest) error {
+	if cl.broken {
+		return cl.networkError()
+	}
 	entry := cl.getEntryByDn(req.DN)
 	if entry == nil {
 		return errors.New("entry not found")
@@ -239,10 +510,135 @@ func (cl *mockClient) Modify(req *ldap.ModifyRequest) error {
 	if entry.DN == cl.entries["entryForErr"].DN {
 		return errors.New("error for tests")
 	}
+	if len(req.Changes) > 0 && req.Changes[0].Modification.Type == "unicodePwd" {
+		return cl.modifyPassword(entry.DN, req.Changes)
+	}
+	return applyMockChanges(entry, req.Changes)
+}
+
+// Minimal password length of mock server.
+const mockMinPasswordLength = 8
+
+// Mimics AD password reset and change. Passwords shorter than minimal length and the current password are rejected.
+func (cl *mockClient) modifyPassword(dn string, changes []ldap.Change) error {
+	var newPassword string
+	switch {
+	case len(changes) == 1 && changes[0].Operation == ldap.ReplaceAttribute:
+		newPassword = changes[0].Modification.Vals[0]
+	case len(changes) == 2 && changes[0].Operation == ldap.DeleteAttribute && changes[1].Operation == ldap.AddAttribute:
+		if changes[0].Modification.Vals[0] != cl.passwords[dn] {
+			return ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New(
+				"00000056: AtrErr: DSID-03190F80, #1:\n\t0: 00000056: DSID-03190F80, problem 1005 (CONSTRAINT_ATT_TYPE), data 0, Att 9005a (unicodePwd)\n",
+			))
+		}
+		newPassword = changes[1].Modification.Vals[0]
+	default:
+		return ldap.NewError(ldap.LDAPResultUnwillingToPerform, errors.New("0000001F: SvcErr: DSID-031A12D2, problem 5003 (WILL_NOT_PERFORM), data 0"))
+	}
+
+	// Encoded password is quoted UTF-16.
+	if len(newPassword) < (mockMinPasswordLength+2)*2 || newPassword == cl.passwords[dn] {
+		return ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New(
+			"0000052D: Constraint violation - check_password_restrictions: the password does not meet the complexity criteria!",
+		))
+	}
+	cl.passwords[dn] = newPassword
 	return nil
 }
 
-func (cl *mockClient) ModifyDN(*ldap.ModifyDNRequest) error { return nil }
+// Applies modify request changes to entry. Changes are applied atomically like AD does.
+func applyMockChanges(entry *ldap.Entry, changes []ldap.Change) error {
+	attrs := make([]*ldap.EntryAttribute, 0, len(entry.Attributes))
+	for _, a := range entry.Attributes {
+		attrs = append(attrs, &ldap.EntryAttribute{Name: a.Name, Values: slices.Clone(a.Values), ByteValues: slices.Clone(a.ByteValues)})
+	}
+	find := func(name string) int {
+		return slices.IndexFunc(attrs, func(a *ldap.EntryAttribute) bool { return strings.EqualFold(a.Name, name) })
+	}
+
+	for _, c := range changes {
+		name, vals := c.Modification.Type, c.Modification.Vals
+		i := find(name)
+		switch c.Operation {
+		case ldap.ReplaceAttribute:
+			if i >= 0 {
+				attrs = slices.Delete(attrs, i, i+1)
+			}
+			if len(vals) > 0 {
+				attrs = append(attrs, &ldap.EntryAttribute{Name: name, Values: slices.Clone(vals)})
+			}
+		case ldap.AddAttribute:
+			if i < 0 {
+				attrs = append(attrs, &ldap.EntryAttribute{Name: name})
+				i = len(attrs) - 1
+			}
+			for _, v := range vals {
+				if slices.Contains(attrs[i].Values, v) {
+					return ldap.NewError(ldap.LDAPResultAttributeOrValueExists, fmt.Errorf("value of '%s' exists", name))
+				}
+				attrs[i].Values = append(attrs[i].Values, v)
+			}
+			attrs[i].ByteValues = nil
+		case ldap.DeleteAttribute:
+			if i < 0 {
+				return ldap.NewError(ldap.LDAPResultNoSuchAttribute, fmt.Errorf("no attribute '%s'", name))
+			}
+			for _, v := range vals {
+				j := slices.Index(attrs[i].Values, v)
+				if j < 0 {
+					return ldap.NewError(ldap.LDAPResultNoSuchAttribute, fmt.Errorf("no value of '%s'", name))
+				}
+				attrs[i].Values = slices.Delete(attrs[i].Values, j, j+1)
+			}
+			attrs[i].ByteValues = nil
+			if len(vals) == 0 || len(attrs[i].Values) == 0 {
+				attrs = slices.Delete(attrs, i, i+1)
+			}
+		}
+	}
+	entry.Attributes = attrs
+	return nil
+}
+
+// Mimics AD move and rename: entry DN, filters with the DN, RDN attribute and name are updated.
+func (cl *mockClient) ModifyDN(req *ldap.ModifyDNRequest) error {
+	if cl.broken {
+		return cl.networkError()
+	}
+	entry := cl.getEntryByDn(req.DN)
+	if entry == nil {
+		return ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("entry '%s' not found", req.DN))
+	}
+	_, parent, err := splitDN(req.DN)
+	if err != nil {
+		return ldap.NewError(ldap.LDAPResultInvalidDNSyntax, err)
+	}
+	if req.NewSuperior != "" {
+		parent = req.NewSuperior
+	}
+	newDN := req.NewRDN + "," + parent
+	if cl.getEntryByDn(newDN) != nil {
+		return ldap.NewError(ldap.LDAPResultEntryAlreadyExists, fmt.Errorf("entry '%s' exists", newDN))
+	}
+	rdn, err := ldap.ParseDN(req.NewRDN)
+	if err != nil || len(rdn.RDNs) != 1 {
+		return ldap.NewError(ldap.LDAPResultInvalidDNSyntax, fmt.Errorf("invalid RDN '%s'", req.NewRDN))
+	}
+	rdnAttr := rdn.RDNs[0].Attributes[0]
+
+	for _, a := range entry.Attributes {
+		if a.Name == mockFiltersAttribute {
+			for i, f := range a.Values {
+				a.Values[i] = strings.ReplaceAll(f, ldap.EscapeFilter(entry.DN), ldap.EscapeFilter(newDN))
+			}
+		}
+	}
+	entry.DN = newDN
+	return applyMockChanges(entry, []ldap.Change{
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: strings.ToLower(rdnAttr.Type), Vals: []string{rdnAttr.Value}}},
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "name", Vals: []string{rdnAttr.Value}}},
+	})
+}
 
 func (cl *mockClient) ModifyWithResult(*ldap.ModifyRequest) (*ldap.ModifyResult, error) {
 	return nil, nil
@@ -256,16 +652,74 @@ func (cl *mockClient) PasswordModify(*ldap.PasswordModifyRequest) (*ldap.Passwor
 	return nil, nil
 }
 
+// Mimics broken connection.
+func (cl *mockClient) networkError() error {
+	cl.Close()
+	return ldap.NewError(ldap.ErrorNetwork, errors.New("connection closed"))
+}
+
 func (cl *mockClient) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if cl.broken {
+		return nil, cl.networkError()
+	}
+	if req.Scope == ldap.ScopeBaseObject && req.BaseDN != "" {
+		entry := cl.getEntryByDn(req.BaseDN)
+		if entry == nil {
+			return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+		}
+		return &ldap.SearchResult{Entries: []*ldap.Entry{entry}}, nil
+	}
 	entries, err := cl.getEntriesByFilter(req.Filter)
 	if err != nil {
 		return nil, err
 	}
-	return &ldap.SearchResult{Entries: entries}, nil
+	return mockSearchPage(req, entries)
 }
 
 func (cl *mockClient) SearchAsync(ctx context.Context, searchRequest *ldap.SearchRequest, bufferSize int) ldap.Response {
-	return nil
+	resp := &mockResponse{ctx: ctx}
+	result, err := cl.Search(searchRequest)
+	if err != nil {
+		resp.err = err
+		return resp
+	}
+	resp.entries = result.Entries
+	resp.controls = result.Controls
+	return resp
+}
+
+// Mock search response. Implements ldap response interface.
+var _ ldap.Response = (*mockResponse)(nil)
+
+type mockResponse struct {
+	ctx      context.Context
+	entries  []*ldap.Entry
+	entry    *ldap.Entry
+	controls []ldap.Control
+	err      error
+}
+
+func (r *mockResponse) Entry() *ldap.Entry { return r.entry }
+
+func (r *mockResponse) Referral() string { return "" }
+
+// Controls are returned with the last entry, as go-ldap returns them with search done message.
+func (r *mockResponse) Controls() []ldap.Control {
+	if len(r.entries) > 0 {
+		return nil
+	}
+	return r.controls
+}
+
+func (r *mockResponse) Err() error { return r.err }
+
+// Mimics go-ldap behavior, that silently stops on context cancellation.
+func (r *mockResponse) Next() bool {
+	if r.err != nil || r.ctx.Err() != nil || len(r.entries) == 0 {
+		return false
+	}
+	r.entry, r.entries = r.entries[0], r.entries[1:]
+	return true
 }
 
 func (cl *mockClient) SearchWithPaging(searchRequest *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
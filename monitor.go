@@ -0,0 +1,123 @@
+package adc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Connection lifecycle callbacks. Callbacks are called synchronously and should not block.
+type Hooks struct {
+	// Called after client connected to server.
+	OnConnect func(server string)
+	// Called when client is disconnected or connection loss is detected. Error is nil for Disconnect call.
+	OnDisconnect func(err error)
+	// Called before each reconnect attempt.
+	OnReconnectAttempt func(attempt int)
+	// Called when reconnect attempt failed.
+	OnReconnectFailed func(attempt int, err error)
+}
+
+// Specifies connection lifecycle callbacks.
+func WithHooks(h Hooks) Option {
+	return func(cl *Client) { cl.hooks = h }
+}
+
+func (h Hooks) connect(server string) {
+	if h.OnConnect != nil {
+		h.OnConnect(server)
+	}
+}
+
+func (h Hooks) disconnect(err error) {
+	if h.OnDisconnect != nil {
+		h.OnDisconnect(err)
+	}
+}
+
+func (h Hooks) reconnectAttempt(attempt int) {
+	if h.OnReconnectAttempt != nil {
+		h.OnReconnectAttempt(attempt)
+	}
+}
+
+func (h Hooks) reconnectFailed(attempt int, err error) {
+	if h.OnReconnectFailed != nil {
+		h.OnReconnectFailed(attempt, err)
+	}
+}
+
+// Starts background monitor that reads root DSE each interval and reconnects as soon as the read fails,
+// so broken connections are replaced before requests fail. Reconnect attempts are delayed according to retry policy.
+// Monitor stops when provided context is done or client is disconnected. Starting new monitor stops the previous one.
+func (cl *Client) StartMonitor(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.pool == nil {
+		return ErrNotConnected
+	}
+	if cl.stopMonitor != nil {
+		cl.stopMonitor()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cl.stopMonitor = cancel
+
+	go cl.monitor(ctx, interval)
+	return nil
+}
+
+func (cl *Client) monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cl.checkHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Probes connection and reconnects until success or context is done if probe failed.
+func (cl *Client) checkHealth(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, cl.Config.Timeout)
+	err := cl.withConn(probeCtx, func(conn ldap.Client) error {
+		_, err := searchConn(probeCtx, conn, cl.rootDSERequest())
+		return err
+	})
+	cancel()
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	cl.logger.Debugf("Connection check failed: %s", err.Error())
+	cl.hooks.disconnect(err)
+
+	for attempt := 1; ; attempt++ {
+		cl.hooks.reconnectAttempt(attempt)
+		err := cl.openPool(ctx, true)
+		if err == nil {
+			cl.logger.Debug("Successfully reconneted to AD server")
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		cl.hooks.reconnectFailed(attempt, err)
+
+		timer := time.NewTimer(cl.Config.Retry.backoff(attempt - 1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package adc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Records hooks calls.
+type testHooks struct {
+	mu             sync.Mutex
+	connects       []string
+	disconnects    []error
+	attempts       []int
+	failedAttempts []int
+}
+
+func (h *testHooks) hooks() Hooks {
+	return Hooks{
+		OnConnect: func(server string) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.connects = append(h.connects, server)
+		},
+		OnDisconnect: func(err error) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.disconnects = append(h.disconnects, err)
+		},
+		OnReconnectAttempt: func(attempt int) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.attempts = append(h.attempts, attempt)
+		},
+		OnReconnectFailed: func(attempt int, err error) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.failedAttempts = append(h.failedAttempts, attempt)
+		},
+	}
+}
+
+func (h *testHooks) counts() (connects, disconnects, attempts, failed int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.connects), len(h.disconnects), len(h.attempts), len(h.failedAttempts)
+}
+
+func Test_Hooks(t *testing.T) {
+	h := &testHooks{}
+	cl := newMockClient(&Config{URL: "ldap://dc1:389", Bind: validMockBind}, WithHooks(h.hooks()))
+	require.NoError(t, cl.Connect())
+	require.NoError(t, cl.Disconnect())
+
+	require.Equal(t, []string{"ldap://dc1:389"}, h.connects)
+	require.Equal(t, []error{nil}, h.disconnects)
+
+	// Zero hooks are no-op.
+	cl = newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+	require.NoError(t, cl.Disconnect())
+}
+
+func Test_Client_StartMonitor(t *testing.T) {
+	retry := &RetryConfig{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	t.Run("NotConnected", func(t *testing.T) {
+		cl := newMockClient(&Config{Bind: validMockBind})
+		require.ErrorIs(t, cl.StartMonitor(context.Background(), time.Millisecond), ErrNotConnected)
+	})
+	t.Run("Healthy", func(t *testing.T) {
+		h := &testHooks{}
+		cl := newMockClient(&Config{Bind: validMockBind}, WithHooks(h.hooks()))
+		require.NoError(t, cl.Connect())
+		require.NoError(t, cl.StartMonitor(context.Background(), time.Millisecond))
+
+		time.Sleep(50 * time.Millisecond)
+		connects, disconnects, attempts, _ := h.counts()
+		require.Equal(t, 1, connects)
+		require.Zero(t, disconnects)
+		require.Zero(t, attempts)
+		require.NoError(t, cl.Disconnect())
+	})
+	t.Run("Reconnect", func(t *testing.T) {
+		h := &testHooks{}
+		cl := newMockClient(&Config{Bind: validMockBind, Retry: retry}, WithHooks(h.hooks()))
+		connectWithBrokenConns(t, cl, 1)
+		require.NoError(t, cl.StartMonitor(context.Background(), time.Millisecond))
+
+		require.Eventually(t, func() bool {
+			connects, _, _, _ := h.counts()
+			return connects == 1
+		}, time.Second, time.Millisecond)
+		require.True(t, cl.ConnectedStatus())
+
+		h.mu.Lock()
+		require.Error(t, h.disconnects[0])
+		require.Equal(t, 1, h.attempts[0])
+		h.mu.Unlock()
+		require.NoError(t, cl.Disconnect())
+	})
+	t.Run("ReconnectFailed", func(t *testing.T) {
+		h := &testHooks{}
+		cl := newMockClient(&Config{URL: "ldap://down:389", Bind: validMockBind, Retry: retry}, WithHooks(h.hooks()))
+		connectWithBrokenConns(t, cl, 1)
+		require.NoError(t, cl.StartMonitor(context.Background(), time.Millisecond))
+
+		require.Eventually(t, func() bool {
+			_, _, _, failed := h.counts()
+			return failed >= 2
+		}, time.Second, time.Millisecond)
+
+		// Disconnect stops the monitor.
+		require.NoError(t, cl.Disconnect())
+		_, _, attempts, _ := h.counts()
+		time.Sleep(50 * time.Millisecond)
+		_, _, after, _ := h.counts()
+		require.LessOrEqual(t, after, attempts+1)
+		require.Nil(t, cl.getPool())
+		require.False(t, cl.ConnectedStatus())
+	})
+	t.Run("StopsOnContext", func(t *testing.T) {
+		h := &testHooks{}
+		cl := newMockClient(&Config{Bind: validMockBind, Retry: retry}, WithHooks(h.hooks()))
+		require.NoError(t, cl.Connect())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, cl.StartMonitor(ctx, time.Millisecond))
+		cancel()
+
+		// Broken connections aren't replaced by stopped monitor.
+		connectWithBrokenConns(t, cl, 1)
+		time.Sleep(50 * time.Millisecond)
+		connects, disconnects, _, _ := h.counts()
+		require.Equal(t, 1, connects)
+		require.Zero(t, disconnects)
+		require.NoError(t, cl.Disconnect())
+	})
+}
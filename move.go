@@ -0,0 +1,99 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Splits DN to its first RDN and parent DN keeping their original form.
+func splitDN(dn string) (string, string, error) {
+	if _, err := ldap.ParseDN(dn); err != nil {
+		return "", "", fmt.Errorf("invalid DN '%s': %w", dn, err)
+	}
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			return strings.TrimSpace(dn[:i]), strings.TrimSpace(dn[i+1:]), nil
+		}
+	}
+	return dn, "", nil
+}
+
+// Moves and/or renames object with ModifyDN request and returns its new DN.
+// Empty newRDN keeps the current RDN, e.g. 'CN=John Smith', and empty newSuperior keeps the current parent.
+// AD updates RDN attribute, e.g. cn, and name attribute of the object automatically.
+func (cl *Client) MoveObject(dn, newRDN, newSuperior string) (string, error) {
+	return cl.MoveObjectContext(context.Background(), dn, newRDN, newSuperior)
+}
+
+// Context-aware version of MoveObject.
+func (cl *Client) MoveObjectContext(ctx context.Context, dn, newRDN, newSuperior string) (string, error) {
+	rdn, parent, err := splitDN(dn)
+	if err != nil {
+		return "", err
+	}
+	if newRDN == "" && newSuperior == "" {
+		return "", errors.New("neither of new RDN or new superior provided")
+	}
+	if newRDN == "" {
+		newRDN = rdn
+	}
+	if newSuperior != "" {
+		if _, err := ldap.ParseDN(newSuperior); err != nil {
+			return "", fmt.Errorf("invalid DN '%s': %w", newSuperior, err)
+		}
+		parent = newSuperior
+	}
+
+	req := ldap.NewModifyDNRequest(dn, newRDN, true, newSuperior)
+	if err := cl.modifyDNRequest(ctx, req); err != nil {
+		return "", err
+	}
+	if parent == "" {
+		return newRDN, nil
+	}
+	return newRDN + "," + parent, nil
+}
+
+// Moves user to another OU keeping its CN. Returns new user DN.
+func (cl *Client) MoveUser(dn, newParentOU string) (string, error) {
+	return cl.MoveUserContext(context.Background(), dn, newParentOU)
+}
+
+// Context-aware version of MoveUser.
+func (cl *Client) MoveUserContext(ctx context.Context, dn, newParentOU string) (string, error) {
+	if newParentOU == "" {
+		return "", errors.New("new parent OU not provided")
+	}
+	return cl.MoveObjectContext(ctx, dn, "", newParentOU)
+}
+
+// Renames user in its OU and returns new user DN. AD updates cn and name attributes to the new CN.
+// displayName is set to the new CN as well if requested.
+func (cl *Client) RenameUser(dn, newCN string, updateDisplayName bool) (string, error) {
+	return cl.RenameUserContext(context.Background(), dn, newCN, updateDisplayName)
+}
+
+// Context-aware version of RenameUser.
+func (cl *Client) RenameUserContext(ctx context.Context, dn, newCN string, updateDisplayName bool) (string, error) {
+	if newCN == "" {
+		return "", errors.New("new CN not provided")
+	}
+	newDN, err := cl.MoveObjectContext(ctx, dn, "CN="+ldap.EscapeDN(newCN), "")
+	if err != nil {
+		return "", err
+	}
+	if !updateDisplayName {
+		return newDN, nil
+	}
+	if err := cl.updateAttribute(ctx, newDN, "displayName", []string{newCN}); err != nil {
+		return newDN, fmt.Errorf("user renamed to '%s', but displayName isn't updated: %w", newDN, err)
+	}
+	return newDN, nil
+}
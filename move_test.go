@@ -0,0 +1,74 @@
+package adc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitDN(t *testing.T) {
+	rdn, parent, err := splitDN("CN=Smith\\, John,OU=org,DC=company,DC=com")
+	require.NoError(t, err)
+	require.Equal(t, "CN=Smith\\, John", rdn)
+	require.Equal(t, "OU=org,DC=company,DC=com", parent)
+
+	rdn, parent, err = splitDN("DC=com")
+	require.NoError(t, err)
+	require.Equal(t, "DC=com", rdn)
+	require.Empty(t, parent)
+
+	_, _, err = splitDN("invalid")
+	require.Error(t, err)
+}
+
+func Test_Client_MoveObject(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	t.Run("MoveUser", func(t *testing.T) {
+		newDN, err := cl.MoveUser(mockOrgDN("dev1"), "OU=sales,DC=company,DC=com")
+		require.NoError(t, err)
+		require.Equal(t, "CN=dev1,OU=sales,DC=company,DC=com", newDN)
+
+		user, err := cl.GetUser(GetUserArgs{Dn: newDN, SkipGroupsSearch: true})
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.Equal(t, "dev1", user.Id)
+
+		user, err = cl.GetUser(GetUserArgs{Dn: mockOrgDN("dev1"), SkipGroupsSearch: true})
+		require.NoError(t, err)
+		require.Nil(t, user)
+	})
+	t.Run("RenameUser", func(t *testing.T) {
+		newDN, err := cl.RenameUser(mockOrgDN("dev2"), "Smith, John", true)
+		require.NoError(t, err)
+		require.Equal(t, "CN=Smith\\, John,OU=org,DC=company,DC=com", newDN)
+
+		entry, err := cl.readEntry(context.Background(), newDN, []string{"cn", "name", "displayName"})
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		require.Equal(t, "Smith, John", entry.GetAttributeValue("displayName"))
+		require.Equal(t, "Smith, John", entry.GetAttributeValue("cn"))
+		require.Equal(t, "Smith, John", entry.GetAttributeValue("name"))
+	})
+	t.Run("MoveAndRename", func(t *testing.T) {
+		newDN, err := cl.MoveObject(mockOrgDN("cycleA"), "CN=cycleC", "OU=sales,DC=company,DC=com")
+		require.NoError(t, err)
+		require.Equal(t, "CN=cycleC,OU=sales,DC=company,DC=com", newDN)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := cl.MoveObject(mockOrgDN("ceo"), "", "")
+		require.Error(t, err)
+		_, err = cl.MoveUser(mockOrgDN("ceo"), "invalid")
+		require.Error(t, err)
+		_, err = cl.RenameUser(mockOrgDN("ceo"), "", false)
+		require.Error(t, err)
+
+		_, err = cl.MoveUser("CN=unknown,OU=org,DC=company,DC=com", "OU=sales,DC=company,DC=com")
+		require.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject))
+		_, err = cl.RenameUser(mockOrgDN("ceo"), "cto", false)
+		require.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists))
+	})
+}
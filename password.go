@@ -0,0 +1,308 @@
+package adc
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// Returned when old password provided to change password is wrong.
+	ErrWrongPassword = errors.New("old password is wrong")
+	// Returned when new password doesn't meet length, complexity, history or minimum password age requirements.
+	ErrPasswordRestriction = errors.New("password doesn't meet password policy requirements")
+	// Returned when bound account isn't permitted to change or reset password.
+	ErrPasswordAccessDenied = errors.New("password change isn't permitted")
+	// Returned when password is modified over connection without TLS.
+	ErrPasswordRequiresTLS = errors.New("password change requires encrypted connection")
+)
+
+// AD system error codes in diagnostic messages of password modify errors.
+const (
+	adErrorAccessDenied        = 0x5
+	adErrorGenFailure          = 0x1F
+	adErrorInvalidPassword     = 0x56
+	adErrorPasswordRestriction = 0x52D
+)
+
+// AD diagnostic message starts with hex system error code, e.g. '0000052D: Constraint violation'.
+var adErrorCodeRe = regexp.MustCompile(`^([0-9A-Fa-f]{8}):`)
+
+// Returns AD system error code from LDAP error diagnostic message.
+func adErrorCode(err error) (uint32, bool) {
+	var ldapErr *ldap.Error
+	if !errors.As(err, &ldapErr) || ldapErr.Err == nil {
+		return 0, false
+	}
+	m := adErrorCodeRe.FindStringSubmatch(ldapErr.Err.Error())
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(m[1], 16, 32)
+	return uint32(code), err == nil
+}
+
+// Wraps password modify error with the rejection reason.
+func passwordError(err error) error {
+	code, ok := adErrorCode(err)
+	if !ok {
+		return err
+	}
+	switch code {
+	case adErrorInvalidPassword:
+		return fmt.Errorf("%w: %w", ErrWrongPassword, err)
+	case adErrorPasswordRestriction:
+		return fmt.Errorf("%w: %w", ErrPasswordRestriction, err)
+	case adErrorAccessDenied:
+		return fmt.Errorf("%w: %w", ErrPasswordAccessDenied, err)
+	case adErrorGenFailure:
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			return fmt.Errorf("%w: %w", ErrPasswordRequiresTLS, err)
+		}
+	}
+	return err
+}
+
+// Password policy of the domain or fine-grained password policy applied to user.
+type PasswordPolicy struct {
+	// DN of fine-grained password settings object. Empty for default domain policy.
+	DN string `json:"dn"`
+	// Minimal number of characters.
+	MinLength int `json:"min_length"`
+	// Password must meet complexity requirements.
+	Complexity bool `json:"complexity"`
+	// Number of previous passwords that can't be reused.
+	HistoryLength int `json:"history_length"`
+	// Period after which password expires. Zero means passwords never expire.
+	MaxAge time.Duration `json:"max_age"`
+	// Period before password can be changed again.
+	MinAge time.Duration `json:"min_age"`
+}
+
+// Flag of domain pwdProperties attribute that requires complex passwords.
+const domainPasswordComplex = 0x1
+
+// Returns default domain password policy.
+func (cl *Client) GetPasswordPolicy() (*PasswordPolicy, error) {
+	return cl.GetPasswordPolicyContext(context.Background())
+}
+
+// Context-aware version of GetPasswordPolicy.
+func (cl *Client) GetPasswordPolicyContext(ctx context.Context) (*PasswordPolicy, error) {
+	entry, err := cl.readDomain(ctx, []string{"minPwdLength", "pwdProperties", "pwdHistoryLength", "maxPwdAge", "minPwdAge"})
+	if err != nil {
+		return nil, err
+	}
+	minLength, _ := strconv.Atoi(entry.GetAttributeValue("minPwdLength"))
+	properties, _ := strconv.Atoi(entry.GetAttributeValue("pwdProperties"))
+	historyLength, _ := strconv.Atoi(entry.GetAttributeValue("pwdHistoryLength"))
+	return &PasswordPolicy{
+		MinLength:     minLength,
+		Complexity:    properties&domainPasswordComplex != 0,
+		HistoryLength: historyLength,
+		MaxAge:        ParseInterval(entry.GetAttributeValue("maxPwdAge")),
+		MinAge:        ParseInterval(entry.GetAttributeValue("minPwdAge")),
+	}, nil
+}
+
+// Returns password policy applied to user: resultant fine-grained password policy if any, or default domain policy.
+func (cl *Client) GetUserPasswordPolicy(dn string) (*PasswordPolicy, error) {
+	return cl.GetUserPasswordPolicyContext(context.Background(), dn)
+}
+
+// Context-aware version of GetUserPasswordPolicy.
+func (cl *Client) GetUserPasswordPolicyContext(ctx context.Context, dn string) (*PasswordPolicy, error) {
+	entry, err := cl.readEntry(ctx, dn, []string{"msDS-ResultantPSO"})
+	if err != nil {
+		return nil, fmt.Errorf("can't get account: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("account '%s' not found", dn)
+	}
+	psoDN := entry.GetAttributeValue("msDS-ResultantPSO")
+	if psoDN == "" {
+		return cl.GetPasswordPolicyContext(ctx)
+	}
+
+	pso, err := cl.readEntry(ctx, psoDN, []string{
+		"msDS-MinimumPasswordLength", "msDS-PasswordComplexityEnabled", "msDS-PasswordHistoryLength",
+		"msDS-MaximumPasswordAge", "msDS-MinimumPasswordAge",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't get password settings object: %w", err)
+	}
+	if pso == nil {
+		return nil, fmt.Errorf("password settings object '%s' not found", psoDN)
+	}
+	minLength, _ := strconv.Atoi(pso.GetAttributeValue("msDS-MinimumPasswordLength"))
+	historyLength, _ := strconv.Atoi(pso.GetAttributeValue("msDS-PasswordHistoryLength"))
+	return &PasswordPolicy{
+		DN:            pso.DN,
+		MinLength:     minLength,
+		Complexity:    strings.EqualFold(pso.GetAttributeValue("msDS-PasswordComplexityEnabled"), "TRUE"),
+		HistoryLength: historyLength,
+		MaxAge:        ParseInterval(pso.GetAttributeValue("msDS-MaximumPasswordAge")),
+		MinAge:        ParseInterval(pso.GetAttributeValue("msDS-MinimumPasswordAge")),
+	}, nil
+}
+
+// Checks password against policy applied to user before it's set.
+// User DN is required, account name and display name are re-read from AD when DN points to an account.
+// Returns ErrPasswordRestriction wrapped with the reason if password would be rejected.
+// History and minimum age rules can't be checked locally, so AD can still reject valid password.
+func (cl *Client) ValidatePassword(user *User, password string) error {
+	return cl.ValidatePasswordContext(context.Background(), user, password)
+}
+
+// Context-aware version of ValidatePassword.
+func (cl *Client) ValidatePasswordContext(ctx context.Context, user *User, password string) error {
+	if user == nil || user.DN == "" {
+		return errors.New("user DN not provided")
+	}
+	policy, err := cl.GetUserPasswordPolicyContext(ctx, user.DN)
+	if err != nil {
+		return fmt.Errorf("can't get password policy: %w", err)
+	}
+	account, err := cl.readEntry(ctx, user.DN, []string{"sAMAccountName", "displayName"})
+	if err != nil {
+		return fmt.Errorf("can't get account: %w", err)
+	}
+	// Provided attributes are kept if DN doesn't point to an account.
+	if account != nil && account.GetAttributeValue("sAMAccountName") != "" {
+		user = &User{DN: account.DN, Attributes: entryAttributes(account)}
+	}
+	return policy.Validate(user, password)
+}
+
+// Characters that AD counts as special ones in complexity check.
+const passwordSpecialChars = "~!@#$%^&*_-+=`|\\(){}[]:;\"'<>,.?/"
+
+// Delimiters of display name tokens in complexity check.
+const displayNameDelimiters = ",.-_ #\t"
+
+// Checks password against policy length and complexity requirements.
+// sAMAccountName and displayName attributes of provided user are used in complexity check, user can be nil.
+// Returns ErrPasswordRestriction wrapped with the reason if password doesn't meet requirements.
+func (p *PasswordPolicy) Validate(user *User, password string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		return fmt.Errorf("%w: password must be at least %d characters long", ErrPasswordRestriction, p.MinLength)
+	}
+	if !p.Complexity {
+		return nil
+	}
+
+	lower := strings.ToLower(password)
+	if user != nil {
+		// Account name is checked only if it's at least 3 characters long.
+		if name := user.GetStringAttribute("sAMAccountName"); utf8.RuneCountInString(name) >= 3 && strings.Contains(lower, strings.ToLower(name)) {
+			return fmt.Errorf("%w: password must not contain account name", ErrPasswordRestriction)
+		}
+		tokens := strings.FieldsFunc(user.GetStringAttribute("displayName"), func(r rune) bool {
+			return strings.ContainsRune(displayNameDelimiters, r)
+		})
+		for _, token := range tokens {
+			if utf8.RuneCountInString(token) >= 3 && strings.Contains(lower, strings.ToLower(token)) {
+				return fmt.Errorf("%w: password must not contain parts of display name", ErrPasswordRestriction)
+			}
+		}
+	}
+
+	if passwordCategories(password) < 3 {
+		return fmt.Errorf("%w: password must contain characters from three of the following categories: "+
+			"uppercase letters, lowercase letters, digits, special characters, other letters", ErrPasswordRestriction)
+	}
+	return nil
+}
+
+// Returns number of AD complexity categories of password characters.
+func passwordCategories(password string) int {
+	var upper, lower, digit, special, other bool
+	for _, r := range password {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsLetter(r):
+			other = true
+		case strings.ContainsRune(passwordSpecialChars, r):
+			special = true
+		}
+	}
+	count := 0
+	for _, ok := range []bool{upper, lower, digit, special, other} {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// Character sets of generated passwords. Similar looking characters are excluded.
+var generatedPasswordCharsets = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!#$%&*+-=?@^_~",
+}
+
+// Default length of generated passwords.
+const defaultGeneratedPasswordLength = 16
+
+// Generates random password that meets policy length and complexity requirements.
+// Password length is raised to policy minimal length. Default length is 16 characters.
+func (p *PasswordPolicy) GeneratePassword(length int) (string, error) {
+	if length <= 0 {
+		length = defaultGeneratedPasswordLength
+	}
+	length = max(length, p.MinLength, len(generatedPasswordCharsets))
+
+	var all string
+	chars := make([]byte, 0, length)
+	// Every character set is used at least once.
+	for _, charset := range generatedPasswordCharsets {
+		c, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+		all += charset
+	}
+	for len(chars) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+
+	// Fisher-Yates shuffle to not keep character sets order.
+	for i := len(chars) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		chars[i], chars[j.Int64()] = chars[j.Int64()], chars[i]
+	}
+	return string(chars), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+	return charset[i.Int64()], nil
+}
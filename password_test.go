@@ -0,0 +1,184 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_passwordError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "WrongPassword",
+			err:  ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New("00000056: AtrErr: DSID-03190F80, #1")),
+			want: ErrWrongPassword,
+		},
+		{
+			name: "Restriction",
+			err:  ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New("0000052D: Constraint violation - check_password_restrictions")),
+			want: ErrPasswordRestriction,
+		},
+		{
+			name: "AccessDenied",
+			err:  ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("00000005: SecErr: DSID-031A11E5, problem 4003 (INSUFF_ACCESS_RIGHTS)")),
+			want: ErrPasswordAccessDenied,
+		},
+		{
+			name: "RequiresTLS",
+			err:  ldap.NewError(ldap.LDAPResultUnwillingToPerform, errors.New("0000001F: SvcErr: DSID-031A12D2, problem 5003 (WILL_NOT_PERFORM)")),
+			want: ErrPasswordRequiresTLS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := passwordError(tt.err)
+			require.ErrorIs(t, err, tt.want)
+			require.True(t, ldap.IsErrorWithCode(err, tt.err.(*ldap.Error).ResultCode), "LDAP error should be kept")
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		other := ldap.NewError(ldap.LDAPResultOther, errors.New("something went wrong"))
+		require.Equal(t, other, passwordError(other))
+		require.Nil(t, passwordError(nil))
+		genFailure := ldap.NewError(ldap.LDAPResultOther, errors.New("0000001F: SvcErr"))
+		require.Equal(t, genFailure, passwordError(genFailure))
+	})
+}
+
+func Test_Client_ChangePassword(t *testing.T) {
+	user1DN := user1MockBind.DN
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	require.ErrorIs(t, cl.ChangePassword(user1DN, "wrongPass", "ZXCVqwwer!@#$1234"), ErrWrongPassword)
+	require.ErrorIs(t, cl.ChangePassword(user1DN, user1MockBind.Password, "short"), ErrPasswordRestriction)
+	require.ErrorIs(t, cl.ChangePassword(user1DN, user1MockBind.Password, user1MockBind.Password), ErrPasswordRestriction)
+
+	require.NoError(t, cl.ChangePassword(user1DN, user1MockBind.Password, "ZXCVqwwer!@#$1234"))
+	require.ErrorIs(t, cl.ChangePassword(user1DN, user1MockBind.Password, "ZXCVqwwer!@#$5678"), ErrWrongPassword, "Old password is changed")
+	require.NoError(t, cl.ChangePassword(user1DN, "ZXCVqwwer!@#$1234", "ZXCVqwwer!@#$5678"))
+
+	require.ErrorIs(t, cl.SetPassword(user1DN, "short", false), ErrPasswordRestriction)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, cl.ChangePasswordContext(ctx, user1DN, "ZXCVqwwer!@#$5678", "ZXCVqwwer!@#$9012"), context.Canceled)
+}
+
+func Test_PasswordPolicy_Validate(t *testing.T) {
+	policy := &PasswordPolicy{MinLength: 8, Complexity: true}
+	user := &User{Attributes: map[string]interface{}{
+		"sAMAccountName": "jsmith",
+		"displayName":    "John Smith-Jones, Jr.",
+	}}
+
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"Qwerty12", true},
+		{"Qwerty1", false},
+		{"qwerty123", false},
+		{"qwerty!23", true},
+		{"QWERTY!@#", false},
+		{"Пароль123", true},
+		{"パスワードabc1", true},
+		{"xJSMITH!x9", false},
+		{"Smith123!", false},
+		{"joneS!2345", false},
+		{"Jr.Jo!2345", true},
+		{"qwerty€£1", false},
+	}
+	for _, tt := range tests {
+		err := policy.Validate(user, tt.password)
+		if tt.valid {
+			require.NoError(t, err, tt.password)
+			continue
+		}
+		require.ErrorIs(t, err, ErrPasswordRestriction, tt.password)
+	}
+
+	require.NoError(t, policy.Validate(nil, "Smith123!"))
+	require.NoError(t, (&PasswordPolicy{MinLength: 4}).Validate(user, "jsmith"))
+	// Short account name isn't checked.
+	require.NoError(t, policy.Validate(&User{Attributes: map[string]interface{}{"sAMAccountName": "jo"}}, "Jo!123456"))
+}
+
+func Test_PasswordPolicy_GeneratePassword(t *testing.T) {
+	policy := &PasswordPolicy{MinLength: 20, Complexity: true}
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		pwd, err := policy.GeneratePassword(0)
+		require.NoError(t, err)
+		require.Len(t, pwd, 20)
+		require.Equal(t, 4, passwordCategories(pwd))
+		require.NoError(t, policy.Validate(nil, pwd))
+		require.False(t, seen[pwd])
+		seen[pwd] = true
+	}
+
+	pwd, err := (&PasswordPolicy{}).GeneratePassword(0)
+	require.NoError(t, err)
+	require.Len(t, pwd, defaultGeneratedPasswordLength)
+
+	pwd, err = (&PasswordPolicy{}).GeneratePassword(2)
+	require.NoError(t, err)
+	require.Len(t, pwd, 4)
+}
+
+func Test_Client_PasswordPolicy(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	domainPolicy := &PasswordPolicy{
+		MinLength:     8,
+		Complexity:    true,
+		HistoryLength: 24,
+		MaxAge:        42 * 24 * time.Hour,
+		MinAge:        24 * time.Hour,
+	}
+	policy, err := cl.GetPasswordPolicy()
+	require.NoError(t, err)
+	require.Equal(t, domainPolicy, policy)
+
+	policy, err = cl.GetUserPasswordPolicy("OU=user1,DC=company,DC=com")
+	require.NoError(t, err)
+	require.Equal(t, domainPolicy, policy)
+
+	policy, err = cl.GetUserPasswordPolicy("OU=user2,DC=company,DC=com")
+	require.NoError(t, err)
+	require.Equal(t, &PasswordPolicy{
+		DN:            "CN=Admins,CN=Password Settings Container,CN=System,DC=company,DC=com",
+		MinLength:     15,
+		Complexity:    true,
+		HistoryLength: 10,
+		MaxAge:        90 * 24 * time.Hour,
+	}, policy)
+
+	_, err = cl.GetUserPasswordPolicy("OU=unknown,DC=company,DC=com")
+	require.ErrorContains(t, err, "not found")
+
+	user1 := &User{DN: "OU=user1,DC=company,DC=com"}
+	require.NoError(t, cl.ValidatePassword(user1, "Qwerty12"))
+	require.ErrorIs(t, cl.ValidatePassword(user1, "User1!abc"), ErrPasswordRestriction)
+
+	user2 := &User{DN: "OU=user2,DC=company,DC=com"}
+	require.ErrorIs(t, cl.ValidatePassword(user2, "Qwerty12"), ErrPasswordRestriction, "Fine-grained policy minimal length")
+	require.ErrorIs(t, cl.ValidatePassword(user2, "Qwerty12!Second"), ErrPasswordRestriction, "Display name token")
+	require.NoError(t, cl.ValidatePassword(user2, "Qwerty12!Qwerty12"))
+
+	require.ErrorContains(t, cl.ValidatePassword(nil, "Qwerty12"), "not provided")
+	require.ErrorContains(t, cl.ValidatePassword(&User{}, "Qwerty12"), "not provided")
+
+	notAccount := &User{DN: "DC=company,DC=com", Attributes: map[string]interface{}{"sAMAccountName": "qwerty"}}
+	require.ErrorIs(t, cl.ValidatePassword(notAccount, "Qwerty12"), ErrPasswordRestriction, "Provided account name")
+}
@@ -0,0 +1,228 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// Returned when operation is requested on not connected client.
+	ErrNotConnected = errors.New("client is not connected")
+	// Returned when connection is requested from closed pool.
+	ErrPoolClosed = errors.New("connections pool is closed")
+)
+
+// Pool of bound connections to AD server.
+type pool struct {
+	cfg  *PoolConfig
+	dial func(ctx context.Context) (ldap.Client, error)
+
+	// Holds a token for every connection checked out or being dialed. Limits pool size.
+	tokens chan struct{}
+
+	mu   sync.Mutex
+	idle []*poolConn
+	// Checked out connections, closed if pool close times out.
+	busy   map[ldap.Client]struct{}
+	closed bool
+	stop   chan struct{}
+}
+
+type poolConn struct {
+	conn     ldap.Client
+	lastUsed time.Time
+}
+
+// Creates new pool and establishes minimal number of connections.
+func newPool(ctx context.Context, cfg *PoolConfig, dial func(ctx context.Context) (ldap.Client, error)) (*pool, error) {
+	p := &pool{
+		cfg:    cfg,
+		dial:   dial,
+		tokens: make(chan struct{}, cfg.MaxSize),
+		busy:   make(map[ldap.Client]struct{}),
+		stop:   make(chan struct{}),
+	}
+	for i := 0; i < cfg.MinSize; i++ {
+		conn, err := dial(ctx)
+		if err != nil {
+			p.closeIdle()
+			return nil, err
+		}
+		p.idle = append(p.idle, &poolConn{conn: conn, lastUsed: time.Now()})
+	}
+	if cfg.IdleTimeout > 0 {
+		go p.reaper(cfg.IdleTimeout)
+	}
+	return p, nil
+}
+
+// Checks out healthy connection from the pool or dials a new one if pool isn't full.
+// Blocks until connection available or context is done.
+func (p *pool) get(ctx context.Context) (ldap.Client, error) {
+	select {
+	case p.tokens <- struct{}{}:
+	case <-p.stop:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.tokens
+		return nil, ErrPoolClosed
+	}
+	for len(p.idle) > 0 {
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if pc.conn.IsClosing() {
+			pc.conn.Close()
+			continue
+		}
+		p.busy[pc.conn] = struct{}{}
+		p.mu.Unlock()
+		return pc.conn, nil
+	}
+	p.mu.Unlock()
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		<-p.tokens
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		conn.Close()
+		<-p.tokens
+		return nil, ErrPoolClosed
+	}
+	p.busy[conn] = struct{}{}
+	return conn, nil
+}
+
+// Returns connection to the pool. Connection is closed if it's broken by provided operation error or pool is closed.
+func (p *pool) put(conn ldap.Client, opErr error) {
+	defer func() { <-p.tokens }()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.busy, conn)
+	if conn.IsClosing() || isNetworkError(opErr) {
+		conn.Close()
+		return
+	}
+	if p.closed {
+		conn.Close()
+		return
+	}
+	p.idle = append(p.idle, &poolConn{conn: conn, lastUsed: time.Now()})
+}
+
+// Closes idle connections that exceed idle timeout, but keeps minimal pool size.
+func (p *pool) reap(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Idle connections are stored starting from the least recently used one.
+	var keep []*poolConn
+	left := len(p.idle)
+	for _, pc := range p.idle {
+		expired := time.Since(pc.lastUsed) > timeout && left > p.cfg.MinSize
+		if expired || pc.conn.IsClosing() {
+			pc.conn.Close()
+			left--
+			continue
+		}
+		keep = append(keep, pc)
+	}
+	p.idle = keep
+}
+
+func (p *pool) reaper(timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.reap(timeout)
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *pool) closeIdle() {
+	for _, pc := range p.idle {
+		pc.conn.Close()
+	}
+	p.idle = nil
+}
+
+// Reports whether pool is open and has checked out or live idle connection.
+func (p *pool) alive() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	if len(p.tokens) > 0 {
+		return true
+	}
+	for _, pc := range p.idle {
+		if !pc.conn.IsClosing() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// Closes the pool. Waits until checked out connections are returned or context is done,
+// then closes checked out connections, so operations in flight on them fail.
+func (p *pool) close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.stop)
+	p.closeIdle()
+	p.mu.Unlock()
+
+	// Connections returned to the closed pool are closed by put.
+	for i := 0; i < cap(p.tokens); i++ {
+		select {
+		case p.tokens <- struct{}{}:
+		case <-ctx.Done():
+			p.closeBusy()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (p *pool) closeBusy() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conn := range p.busy {
+		conn.Close()
+	}
+}
+
+// Checks whether error means that connection is broken or server is unreachable.
+func isNetworkError(err error) bool {
+	return ldap.IsErrorAnyOf(err, ldap.ErrorNetwork, ldap.LDAPResultServerDown)
+}
@@ -0,0 +1,178 @@
+package adc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(t *testing.T, cfg *PoolConfig) (*pool, *atomic.Int32) {
+	dialed := &atomic.Int32{}
+	p, err := newPool(context.Background(), cfg, func(ctx context.Context) (ldap.Client, error) {
+		dialed.Add(1)
+		return mockConnection()
+	})
+	require.NoError(t, err)
+	return p, dialed
+}
+
+func Test_newPool(t *testing.T) {
+	t.Run("DialErr", func(t *testing.T) {
+		p, err := newPool(context.Background(), &PoolConfig{MinSize: 2, MaxSize: 2}, func(ctx context.Context) (ldap.Client, error) {
+			return nil, errors.New("dial error")
+		})
+		require.Error(t, err)
+		require.Nil(t, p)
+	})
+	t.Run("Ok", func(t *testing.T) {
+		p, dialed := newTestPool(t, &PoolConfig{MinSize: 3, MaxSize: 5})
+		require.Equal(t, int32(3), dialed.Load())
+		require.Len(t, p.idle, 3)
+		require.NoError(t, p.close(context.Background()))
+	})
+}
+
+func Test_pool_getPut(t *testing.T) {
+	t.Run("Reuse", func(t *testing.T) {
+		p, dialed := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 2})
+		conn, err := p.get(context.Background())
+		require.NoError(t, err)
+		p.put(conn, nil)
+
+		again, err := p.get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, conn, again)
+		require.Equal(t, int32(1), dialed.Load())
+		p.put(again, nil)
+	})
+	t.Run("MaxSize", func(t *testing.T) {
+		p, dialed := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 2})
+		c1, err := p.get(context.Background())
+		require.NoError(t, err)
+		c2, err := p.get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int32(2), dialed.Load())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = p.get(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		p.put(c1, nil)
+		p.put(c2, nil)
+		require.Len(t, p.idle, 2)
+	})
+	t.Run("DiscardBroken", func(t *testing.T) {
+		p, dialed := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 1})
+		conn, err := p.get(context.Background())
+		require.NoError(t, err)
+		p.put(conn, ldap.NewError(ldap.ErrorNetwork, errors.New("connection error")))
+		require.True(t, conn.IsClosing())
+		require.Empty(t, p.idle)
+
+		conn, err = p.get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int32(2), dialed.Load())
+
+		// Closed connection isn't checked out from idle.
+		p.put(conn, nil)
+		conn.Close()
+		conn, err = p.get(context.Background())
+		require.NoError(t, err)
+		require.False(t, conn.IsClosing())
+		require.Equal(t, int32(3), dialed.Load())
+	})
+}
+
+func Test_pool_reap(t *testing.T) {
+	p, _ := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 3})
+	c1, _ := p.get(context.Background())
+	c2, _ := p.get(context.Background())
+	c3, _ := p.get(context.Background())
+	p.put(c1, nil)
+	p.put(c2, nil)
+	p.put(c3, nil)
+	require.Len(t, p.idle, 3)
+
+	for _, pc := range p.idle {
+		pc.lastUsed = time.Now().Add(-time.Hour)
+	}
+	p.reap(time.Minute)
+	require.Len(t, p.idle, 1)
+	require.Equal(t, c3, p.idle[0].conn, "Most recently used connection should be kept")
+	require.True(t, c1.IsClosing())
+	require.True(t, c2.IsClosing())
+}
+
+func Test_pool_close(t *testing.T) {
+	t.Run("Drain", func(t *testing.T) {
+		p, _ := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 2})
+		conn, err := p.get(context.Background())
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() { done <- p.close(context.Background()) }()
+
+		select {
+		case <-done:
+			t.Fatal("Close should wait for checked out connection")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		p.put(conn, nil)
+		require.NoError(t, <-done)
+		require.True(t, conn.IsClosing(), "Connection returned to closed pool should be closed")
+
+		_, err = p.get(context.Background())
+		require.ErrorIs(t, err, ErrPoolClosed)
+		require.NoError(t, p.close(context.Background()))
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		p, _ := newTestPool(t, &PoolConfig{MinSize: 1, MaxSize: 1})
+		conn, err := p.get(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.close(ctx), context.DeadlineExceeded)
+		require.True(t, p.isClosed())
+		require.True(t, conn.IsClosing(), "Checked out connection should be closed on timeout")
+
+		// Operation that returns the connection later doesn't break the pool.
+		p.put(conn, nil)
+		require.Empty(t, p.idle)
+		require.Empty(t, p.busy)
+	})
+	t.Run("DialAfterClose", func(t *testing.T) {
+		dialing := make(chan struct{})
+		release := make(chan struct{})
+		var dialed ldap.Client
+		p, err := newPool(context.Background(), &PoolConfig{MinSize: 0, MaxSize: 1}, func(ctx context.Context) (ldap.Client, error) {
+			close(dialing)
+			<-release
+			conn, err := mockConnection()
+			dialed = conn
+			return conn, err
+		})
+		require.NoError(t, err)
+
+		errCh := make(chan error)
+		go func() {
+			_, err := p.get(context.Background())
+			errCh <- err
+		}()
+		<-dialing
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.close(ctx), context.DeadlineExceeded)
+
+		close(release)
+		require.ErrorIs(t, <-errCh, ErrPoolClosed)
+		require.True(t, dialed.IsClosing(), "Connection dialed after close should be closed")
+	})
+}
@@ -0,0 +1,118 @@
+package adc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Splits SID in 'S-1-5-...' form to domain SID and relative identifier.
+func splitSID(sid string) (string, uint32, bool) {
+	i := strings.LastIndexByte(sid, '-')
+	if i < 0 {
+		return "", 0, false
+	}
+	rid, err := strconv.ParseUint(sid[i+1:], 10, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	return sid[:i], uint32(rid), true
+}
+
+// Reports whether group with provided ID is user primary group, which membership isn't stored in 'member' attribute.
+func (u *User) isPrimaryGroup(groupId string) bool {
+	return slices.ContainsFunc(u.Groups, func(g UserGroup) bool { return g.Primary && g.Id == groupId })
+}
+
+// Reads attribute with binary SID of the entry and returns it in string form.
+func (cl *Client) readSID(ctx context.Context, dn string, attributes ...string) (string, *ldap.Entry, error) {
+	entry, err := cl.readEntry(ctx, dn, append([]string{"objectSid"}, attributes...))
+	if err != nil || entry == nil {
+		return "", nil, err
+	}
+	attr := entry.GetRawAttributeValue("objectSid")
+	if len(attr) == 0 {
+		return "", entry, nil
+	}
+	sid, err := DecodeSID(attr)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid objectSid of '%s': %w", dn, err)
+	}
+	return sid, entry, nil
+}
+
+// Returns user primary group, which isn't listed in the group 'member' attribute.
+// Primary group SID is user domain SID with primaryGroupID as relative identifier.
+// Returns nil if user or its primary group isn't found.
+func (cl *Client) getUserPrimaryGroup(ctx context.Context, dn string) (*UserGroup, error) {
+	userSID, entry, err := cl.readSID(ctx, dn, "primaryGroupID")
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	domainSID, _, ok := splitSID(userSID)
+	primaryGroupID, err := strconv.ParseUint(entry.GetAttributeValue("primaryGroupID"), 10, 32)
+	if !ok || err != nil {
+		return nil, nil
+	}
+
+	filter, err := lookupFilter(cl.Config.Groups.FilterByGroup, "", fmt.Sprintf("%s-%d", domainSID, primaryGroupID), "", "")
+	if err != nil {
+		return nil, err
+	}
+	req := &ldap.SearchRequest{
+		BaseDN:       cl.Config.Groups.SearchBase,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		TimeLimit:    int(cl.Config.Timeout.Seconds()),
+		Filter:       filter,
+		Attributes:   []string{cl.Config.Groups.IdAttribute},
+	}
+	group, err := cl.searchEntry(ctx, req)
+	if err != nil || group == nil {
+		return nil, err
+	}
+	return &UserGroup{
+		DN:      group.DN,
+		Id:      group.GetAttributeValue(cl.Config.Groups.IdAttribute),
+		Primary: true,
+	}, nil
+}
+
+// Returns users which primary group is the provided group, so they aren't listed in its 'member' attribute.
+func (cl *Client) getPrimaryGroupMembers(ctx context.Context, dn string) ([]GroupMember, error) {
+	groupSID, _, err := cl.readSID(ctx, dn)
+	if err != nil {
+		return nil, err
+	}
+	_, rid, ok := splitSID(groupSID)
+	if !ok {
+		return nil, nil
+	}
+
+	req := &ldap.SearchRequest{
+		BaseDN:       cl.Config.Users.SearchBase,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		TimeLimit:    int(cl.Config.Timeout.Seconds()),
+		Filter:       fmt.Sprintf("(&%s(primaryGroupID=%d))", cl.Config.Users.FilterByPerson, rid),
+		Attributes:   []string{cl.Config.Users.IdAttribute},
+	}
+	// Primary group like Domain Users may have more members than server returns without paging.
+	entries, err := cl.searchPaged(ctx, req, defaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+	var result []GroupMember
+	for _, e := range entries {
+		result = append(result, GroupMember{
+			DN:      e.DN,
+			Id:      e.GetAttributeValue(cl.Config.Users.IdAttribute),
+			Primary: true,
+		})
+	}
+	return result, nil
+}
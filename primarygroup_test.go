@@ -0,0 +1,81 @@
+package adc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitSID(t *testing.T) {
+	domain, rid, ok := splitSID("S-1-5-21-1004336348-1177238915-682003330-513")
+	require.True(t, ok)
+	require.Equal(t, "S-1-5-21-1004336348-1177238915-682003330", domain)
+	require.Equal(t, uint32(513), rid)
+
+	_, _, ok = splitSID("")
+	require.False(t, ok)
+	_, _, ok = splitSID("S-1-5-x")
+	require.False(t, ok)
+}
+
+func Test_Client_PrimaryGroup(t *testing.T) {
+	cl := newMockClient(&Config{Bind: validMockBind})
+	require.NoError(t, cl.Connect())
+
+	domainUsersDN := "CN=Domain Users,CN=Users,DC=company,DC=com"
+	t.Run("UserGroups", func(t *testing.T) {
+		user, err := cl.GetUser(GetUserArgs{Id: "user2"})
+		require.NoError(t, err)
+		require.Equal(t, []UserGroup{
+			{DN: "OU=group2,DC=company,DC=com", Id: "group2"},
+			{DN: domainUsersDN, Id: "Domain Users", Primary: true},
+		}, user.Groups)
+		require.True(t, user.IsGroupMember("Domain Users"))
+	})
+	t.Run("NoPrimaryGroupID", func(t *testing.T) {
+		user, err := cl.GetUser(GetUserArgs{Id: "user1"})
+		require.NoError(t, err)
+		require.False(t, user.IsGroupMember("Domain Users"))
+	})
+	t.Run("GroupMembers", func(t *testing.T) {
+		// Members don't fit into a single page of mock search.
+		group, err := cl.GetGroup(GetGroupArgs{Id: "Domain Users"})
+		require.NoError(t, err)
+		require.Greater(t, len(group.Members), mockSizeLimit)
+		require.Contains(t, group.Members, GroupMember{DN: "OU=user2,DC=company,DC=com", Id: "user2", Primary: true})
+		require.Contains(t, group.Members, GroupMember{DN: mockOrgDN("ceo"), Id: "ceo", Primary: true})
+		for _, m := range group.Members {
+			require.True(t, m.Primary, m.DN)
+		}
+	})
+	t.Run("AddMembers", func(t *testing.T) {
+		added, err := cl.AddGroupMembers("Domain Users", "user1", "user2")
+		require.NoError(t, err)
+		require.Equal(t, 1, added, "Primary group member is already a member")
+
+		group, err := cl.GetGroup(GetGroupArgs{Id: "Domain Users", Attributes: []string{"member"}, SkipMembersSearch: true})
+		require.NoError(t, err)
+		require.Equal(t, "OU=user1,DC=company,DC=com", group.GetStringAttribute("member"),
+			"Primary group members aren't written to 'member' attribute")
+	})
+	t.Run("DeleteMembers", func(t *testing.T) {
+		_, err := cl.DeleteGroupMembers("Domain Users", "user2")
+		require.ErrorContains(t, err, "can't be deleted from its primary group")
+
+		group, err := cl.GetGroup(GetGroupArgs{Id: "Domain Users", Attributes: []string{"member"}})
+		require.NoError(t, err)
+		require.Equal(t, "OU=user1,DC=company,DC=com", group.GetStringAttribute("member"), "'member' attribute isn't rewritten")
+		require.Contains(t, group.MembersDn(), "OU=user2,DC=company,DC=com")
+	})
+}
+
+func Test_Group_directMembersDn(t *testing.T) {
+	group := &Group{Members: []GroupMember{
+		{DN: "direct"},
+		{DN: "primary", Primary: true},
+	}}
+	require.Equal(t, []string{"direct", "primary"}, group.MembersDn())
+	require.Equal(t, []string{"direct"}, group.directMembersDn())
+	require.Equal(t, []string{"direct", "new"}, popAddGroupMembers(group, []string{"new"}))
+	require.Equal(t, []string{}, popDelGroupMembers(group, []string{"direct"}))
+}
@@ -0,0 +1,61 @@
+package adc
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Policy of automatic reconnect and retry of read operations after network failures.
+type RetryConfig struct {
+	// Disables retries. Broken connections are still replaced with new ones on the next operation.
+	Disabled bool `json:"disabled"`
+	// Maximum number of retries of failed read operation. Default is 3.
+	MaxRetries int `json:"max_retries"`
+	// Delay before the first retry. Doubles with each next retry. Default is 100 milliseconds.
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	// Maximum delay between retries. Default is 5 seconds.
+	MaxBackoff time.Duration `json:"max_backoff"`
+}
+
+// Returns delay before provided retry attempt with exponential backoff and jitter.
+// Delay is randomly chosen between half and full backoff value.
+func (cfg *RetryConfig) backoff(attempt int) time.Duration {
+	d := cfg.InitialBackoff
+	for i := 0; i < attempt && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxBackoff {
+		d = cfg.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Runs idempotent operation on pooled connection.
+// Operation is retried on new connection after network failure according to retry policy.
+func (cl *Client) withRetry(ctx context.Context, op func(conn ldap.Client) error) error {
+	policy := cl.Config.Retry
+	for attempt := 0; ; attempt++ {
+		err := cl.withConn(ctx, op)
+		if !isNetworkError(err) || policy.Disabled || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		cl.logger.Debugf("Network failure, retrying in %s. Attempt: %d; Error: %s", delay, attempt+1, err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package adc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// Connects mock client to pool, which first connections are broken.
+func connectWithBrokenConns(t *testing.T, cl *Client, broken int32) *atomic.Int32 {
+	dialed := &atomic.Int32{}
+	p, err := newPool(context.Background(), cl.Config.Pool, func(ctx context.Context) (ldap.Client, error) {
+		conn, err := mockConnection()
+		conn.broken = dialed.Add(1) <= broken
+		return conn, err
+	})
+	require.NoError(t, err)
+	cl.mu.Lock()
+	cl.pool = p
+	cl.mu.Unlock()
+	return dialed
+}
+
+func Test_RetryConfig_backoff(t *testing.T) {
+	cfg := &RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, limit := range []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second,
+	} {
+		for i := 0; i < 10; i++ {
+			d := cfg.backoff(attempt)
+			require.GreaterOrEqual(t, d, limit/2)
+			require.LessOrEqual(t, d, limit)
+		}
+	}
+	require.Equal(t, time.Duration(0), (&RetryConfig{}).backoff(1))
+}
+
+func Test_Client_withRetry(t *testing.T) {
+	retry := &RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	t.Run("ReadRetried", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: retry})
+		dialed := connectWithBrokenConns(t, cl, 2)
+
+		user, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.Equal(t, int32(3), dialed.Load())
+	})
+	t.Run("PagedReadRetried", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: retry})
+		connectWithBrokenConns(t, cl, 1)
+
+		users, err := cl.ListUsers(GetUserArgs{}, 10, "(&(objectClass=person)(sAMAccountName=user1))")
+		require.NoError(t, err)
+		require.Len(t, *users, 1)
+	})
+	t.Run("MaxRetries", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: retry})
+		dialed := connectWithBrokenConns(t, cl, 10)
+
+		_, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.True(t, isNetworkError(err))
+		require.Equal(t, int32(3), dialed.Load())
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: &RetryConfig{Disabled: true}})
+		dialed := connectWithBrokenConns(t, cl, 1)
+
+		_, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.True(t, isNetworkError(err))
+
+		// Broken connection is replaced with a new one.
+		user, err := cl.GetUser(GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.Equal(t, int32(2), dialed.Load())
+	})
+	t.Run("WriteNotRetried", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: retry})
+		dialed := connectWithBrokenConns(t, cl, 1)
+
+		err := cl.UpdateUser("OU=user1,DC=company,DC=com", []ldap.Attribute{{Type: "mail", Vals: []string{"user1@company.com"}}})
+		require.True(t, isNetworkError(err))
+		require.Equal(t, int32(1), dialed.Load())
+
+		err = cl.UpdateUser("OU=user1,DC=company,DC=com", []ldap.Attribute{{Type: "mail", Vals: []string{"user1@company.com"}}})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), dialed.Load())
+	})
+	t.Run("ContextCancel", func(t *testing.T) {
+		cl := newMockClient(&Config{Retry: &RetryConfig{MaxRetries: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}})
+		connectWithBrokenConns(t, cl, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := cl.GetUserContext(ctx, GetUserArgs{Id: "user1", SkipGroupsSearch: true})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,83 @@
+package adc
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Strategy to select AD server from servers list.
+type ServerSelection string
+
+const (
+	// Servers are tried in the configured order. Default strategy.
+	ServerSelectionFailover ServerSelection = "failover"
+	// Servers are tried in random order.
+	ServerSelectionRandom ServerSelection = "random"
+	// Each new connection starts with the next server from the list.
+	ServerSelectionRoundRobin ServerSelection = "round-robin"
+)
+
+// Tracks servers state and orders servers for connection attempts.
+type serverList struct {
+	mu       sync.Mutex
+	next     int
+	failedAt map[string]time.Time
+	current  string
+}
+
+func newServerList() *serverList {
+	return &serverList{failedAt: map[string]time.Time{}}
+}
+
+// Returns provided servers in order of connection attempts.
+// Servers that failed within cooldown period are moved to the end of the list.
+func (sl *serverList) order(urls []string, strategy ServerSelection, cooldown time.Duration) []string {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	ordered := make([]string, len(urls))
+	copy(ordered, urls)
+
+	switch strategy {
+	case ServerSelectionRandom:
+		rand.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+	case ServerSelectionRoundRobin:
+		if len(ordered) > 0 {
+			start := sl.next % len(ordered)
+			sl.next++
+			ordered = append(ordered[start:], ordered[:start]...)
+		}
+	}
+
+	var healthy, cooling []string
+	for _, u := range ordered {
+		if failed, ok := sl.failedAt[u]; ok && time.Since(failed) < cooldown {
+			cooling = append(cooling, u)
+			continue
+		}
+		healthy = append(healthy, u)
+	}
+	return append(healthy, cooling...)
+}
+
+// Marks server as failed to put it in cooldown.
+func (sl *serverList) markFailed(url string) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.failedAt[url] = time.Now()
+}
+
+// Marks server as the one client is connected to.
+func (sl *serverList) markConnected(url string) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	delete(sl.failedAt, url)
+	sl.current = url
+}
+
+func (sl *serverList) currentServer() string {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.current
+}
@@ -0,0 +1,79 @@
+package adc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_serverList_order(t *testing.T) {
+	urls := []string{"ldap://dc1", "ldap://dc2", "ldap://dc3"}
+
+	t.Run("Failover", func(t *testing.T) {
+		sl := newServerList()
+		require.Equal(t, urls, sl.order(urls, ServerSelectionFailover, time.Minute))
+		require.Equal(t, urls, sl.order(urls, ServerSelectionFailover, time.Minute))
+	})
+	t.Run("RoundRobin", func(t *testing.T) {
+		sl := newServerList()
+		require.Equal(t, urls, sl.order(urls, ServerSelectionRoundRobin, time.Minute))
+		require.Equal(t, []string{"ldap://dc2", "ldap://dc3", "ldap://dc1"}, sl.order(urls, ServerSelectionRoundRobin, time.Minute))
+		require.Equal(t, []string{"ldap://dc3", "ldap://dc1", "ldap://dc2"}, sl.order(urls, ServerSelectionRoundRobin, time.Minute))
+		require.Equal(t, urls, sl.order(urls, ServerSelectionRoundRobin, time.Minute))
+	})
+	t.Run("Random", func(t *testing.T) {
+		sl := newServerList()
+		ordered := sl.order(urls, ServerSelectionRandom, time.Minute)
+		require.ElementsMatch(t, urls, ordered)
+		require.Equal(t, []string{"ldap://dc1", "ldap://dc2", "ldap://dc3"}, urls, "Provided list should not be modified")
+	})
+	t.Run("Cooldown", func(t *testing.T) {
+		sl := newServerList()
+		sl.markFailed("ldap://dc1")
+		require.Equal(t, []string{"ldap://dc2", "ldap://dc3", "ldap://dc1"}, sl.order(urls, ServerSelectionFailover, time.Minute))
+		require.Equal(t, urls, sl.order(urls, ServerSelectionFailover, 0), "Server should be back after cooldown")
+
+		sl.markConnected("ldap://dc1")
+		require.Equal(t, urls, sl.order(urls, ServerSelectionFailover, time.Minute))
+		require.Equal(t, "ldap://dc1", sl.currentServer())
+	})
+	t.Run("Empty", func(t *testing.T) {
+		sl := newServerList()
+		require.Empty(t, sl.order(nil, ServerSelectionRoundRobin, time.Minute))
+	})
+}
+
+func Test_Client_Failover(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		cl := newMockClient(&Config{
+			URLs: []string{"ldap://down:389", "ldap://dc2:389"},
+			Bind: validMockBind,
+		})
+		require.Empty(t, cl.CurrentServer())
+		require.NoError(t, cl.Connect())
+		require.Equal(t, "ldap://dc2:389", cl.CurrentServer())
+		require.NoError(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+	})
+	t.Run("CheckAuthKeepsCurrentServer", func(t *testing.T) {
+		cl := newMockClient(&Config{
+			URLs:            []string{"ldap://dc1:389", "ldap://dc2:389"},
+			ServerSelection: ServerSelectionRoundRobin,
+			Bind:            validMockBind,
+		})
+		require.NoError(t, cl.Connect())
+		require.Equal(t, "ldap://dc1:389", cl.CurrentServer())
+		require.NoError(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+		require.Equal(t, "ldap://dc1:389", cl.CurrentServer())
+	})
+	t.Run("AllDown", func(t *testing.T) {
+		cl := newMockClient(&Config{
+			URL:  "ldap://down:389",
+			URLs: []string{"ldap://down:390"},
+			Bind: validMockBind,
+		})
+		require.Error(t, cl.Connect())
+		require.Error(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+		require.Empty(t, cl.CurrentServer())
+	})
+}
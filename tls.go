@@ -0,0 +1,201 @@
+package adc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// TLS mode of connection to AD server.
+type TLSMode string
+
+const (
+	// Plain connection without TLS. Default for 'ldap://' URLs.
+	TLSModeNone TLSMode = "none"
+	// TLS connection from the start. Default for 'ldaps://' URLs.
+	TLSModeLDAPS TLSMode = "ldaps"
+	// Upgrades plain connection via StartTLS. Proceeds in cleartext if server refuses StartTLS.
+	TLSModeStartTLS TLSMode = "starttls"
+	// Upgrades plain connection via StartTLS. Refuses to bind in cleartext.
+	TLSModeStartTLSRequired TLSMode = "starttls-required"
+)
+
+// Returned when StartTLS is required, but connection wasn't upgraded.
+var ErrCleartextBind = errors.New("refusing to bind over cleartext connection")
+
+// Returns TLS mode from config or the one matching server URL scheme if mode isn't set.
+func (cfg *Config) tlsMode(serverURL string) TLSMode {
+	if cfg.TLSMode != "" {
+		return cfg.TLSMode
+	}
+	if strings.HasPrefix(serverURL, "ldaps://") {
+		return TLSModeLDAPS
+	}
+	return TLSModeNone
+}
+
+// Checks that TLS mode is known and matches URL scheme.
+func validateTLSMode(mode TLSMode, serverURL string) error {
+	ldaps := strings.HasPrefix(serverURL, "ldaps://")
+	switch mode {
+	case TLSModeNone:
+		return nil
+	case TLSModeLDAPS:
+		if !ldaps {
+			return fmt.Errorf("TLS mode '%s' requires 'ldaps://' URL", mode)
+		}
+		return nil
+	case TLSModeStartTLS, TLSModeStartTLSRequired:
+		if ldaps {
+			return fmt.Errorf("TLS mode '%s' requires 'ldap://' URL", mode)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown TLS mode '%s'", mode)
+}
+
+// TLS settings of connection to AD server.
+type TLSConfig struct {
+	// Path to PEM encoded CA bundle to verify server certificate. System pool is used if neither CAFile nor CA provided.
+	CAFile string `json:"ca_file"`
+	// PEM encoded CA bundle. Appended to CAFile certificates.
+	CA string `json:"ca"`
+	// Paths to PEM encoded client certificate and key for mutual TLS.
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	// PEM encoded client certificate and key for mutual TLS. Used if CertFile isn't provided.
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+	// Server name to verify certificate against. Sets to URL host if not provided. Useful for connecting by IP.
+	ServerName string `json:"server_name"`
+	// Minimum TLS version: '1.0', '1.1', '1.2' or '1.3'. Default is '1.2'.
+	MinVersion string `json:"min_version"`
+}
+
+// Specifies base TLS config for connections. Settings from Config.TLS are applied on top of it.
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(cl *Client) { cl.tlsBase = tc }
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Builds TLS config for connection to provided server URL.
+func (cl *Client) tlsConfig(serverURL string) (*tls.Config, error) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cl.tlsBase != nil {
+		tc = cl.tlsBase.Clone()
+	}
+	if cl.Config.InsecureTLS {
+		tc.InsecureSkipVerify = true
+	}
+
+	if cfg := cl.Config.TLS; cfg != nil {
+		if err := cfg.apply(tc); err != nil {
+			return nil, err
+		}
+	}
+
+	if tc.ServerName == "" {
+		tc.ServerName = urlHostname(serverURL)
+	}
+	return tc, nil
+}
+
+// Applies settings to provided TLS config.
+func (cfg *TLSConfig) apply(tc *tls.Config) error {
+	if cfg.CAFile != "" || cfg.CA != "" {
+		pool := x509.NewCertPool()
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return fmt.Errorf("can't read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificates found in CA file '%s'", cfg.CAFile)
+			}
+		}
+		if cfg.CA != "" && !pool.AppendCertsFromPEM([]byte(cfg.CA)) {
+			return errors.New("no certificates found in CA")
+		}
+		tc.RootCAs = pool
+	}
+
+	switch {
+	case cfg.CertFile != "" || cfg.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("can't load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	case cfg.Cert != "" || cfg.Key != "":
+		cert, err := tls.X509KeyPair([]byte(cfg.Cert), []byte(cfg.Key))
+		if err != nil {
+			return fmt.Errorf("can't parse client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.ServerName != "" {
+		tc.ServerName = cfg.ServerName
+	}
+
+	if cfg.MinVersion != "" {
+		v, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return fmt.Errorf("unknown TLS version '%s'", cfg.MinVersion)
+		}
+		tc.MinVersion = v
+	}
+	return nil
+}
+
+// Upgrades plain connection via StartTLS according to TLS mode.
+func (cl *Client) startTLS(conn ldap.Client, mode TLSMode, serverURL string) error {
+	if mode != TLSModeStartTLS && mode != TLSModeStartTLSRequired {
+		return nil
+	}
+
+	tc, err := cl.tlsConfig(serverURL)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.StartTLS(tc); err != nil {
+		// Connection is closed by go-ldap on failed handshake and can't be used in cleartext.
+		if mode == TLSModeStartTLSRequired || conn.IsClosing() {
+			return fmt.Errorf("StartTLS failed: %w", err)
+		}
+		cl.logger.Debugf("StartTLS failed, proceeding over cleartext connection: %s", err.Error())
+		return nil
+	}
+
+	if _, ok := conn.TLSConnectionState(); !ok && mode == TLSModeStartTLSRequired {
+		return ErrCleartextBind
+	}
+	return nil
+}
+
+// Returns host name without port from provided URL.
+func urlHostname(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return u.Host
+	}
+	return host
+}
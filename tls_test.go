@@ -0,0 +1,181 @@
+package adc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Generates self-signed certificate and key in PEM format.
+func generateTestCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "adc test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(certPEM), string(keyPEM)
+}
+
+func Test_Config_tlsMode(t *testing.T) {
+	require.Equal(t, TLSModeNone, (&Config{}).tlsMode("ldap://dc:389"))
+	require.Equal(t, TLSModeLDAPS, (&Config{}).tlsMode("ldaps://dc:636"))
+	require.Equal(t, TLSModeStartTLS, (&Config{TLSMode: TLSModeStartTLS}).tlsMode("ldap://dc:389"))
+}
+
+func Test_validateTLSMode(t *testing.T) {
+	require.NoError(t, validateTLSMode(TLSModeNone, "ldap://dc:389"))
+	require.NoError(t, validateTLSMode(TLSModeLDAPS, "ldaps://dc:636"))
+	require.NoError(t, validateTLSMode(TLSModeStartTLS, "ldap://dc:389"))
+	require.NoError(t, validateTLSMode(TLSModeStartTLSRequired, "ldap://dc:389"))
+
+	require.Error(t, validateTLSMode(TLSModeLDAPS, "ldap://dc:389"))
+	require.Error(t, validateTLSMode(TLSModeStartTLS, "ldaps://dc:636"))
+	require.Error(t, validateTLSMode(TLSModeStartTLSRequired, "ldaps://dc:636"))
+	require.Error(t, validateTLSMode("unknown", "ldap://dc:389"))
+}
+
+func Test_Client_StartTLS(t *testing.T) {
+	t.Run("Upgraded", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://dc:389", TLSMode: TLSModeStartTLSRequired, Bind: validMockBind})
+		conn, _, err := cl.connect(context.Background())
+		require.NoError(t, err)
+		_, ok := conn.TLSConnectionState()
+		require.True(t, ok)
+	})
+	t.Run("OptionalFallback", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://notls:389", TLSMode: TLSModeStartTLS, Bind: validMockBind})
+		conn, _, err := cl.connect(context.Background())
+		require.NoError(t, err)
+		_, ok := conn.TLSConnectionState()
+		require.False(t, ok)
+	})
+	t.Run("RequiredErr", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://notls:389", TLSMode: TLSModeStartTLSRequired, Bind: validMockBind})
+		require.Error(t, cl.Connect())
+		require.Error(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+	})
+	t.Run("PlainNotUpgraded", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldap://dc:389", Bind: validMockBind})
+		conn, _, err := cl.connect(context.Background())
+		require.NoError(t, err)
+		_, ok := conn.TLSConnectionState()
+		require.False(t, ok)
+	})
+	t.Run("BadMode", func(t *testing.T) {
+		cl := newMockClient(&Config{URL: "ldaps://dc:636", TLSMode: TLSModeStartTLS})
+		require.Error(t, cl.Connect())
+	})
+}
+
+func Test_urlHostname(t *testing.T) {
+	require.Equal(t, "dc.company.com", urlHostname("ldap://dc.company.com:389"))
+	require.Equal(t, "dc.company.com", urlHostname("ldaps://dc.company.com"))
+	require.Equal(t, "10.0.0.1", urlHostname("ldap://10.0.0.1:389"))
+	require.Equal(t, "", urlHostname("://bad"))
+}
+
+func Test_Client_tlsConfig(t *testing.T) {
+	certPEM, keyPEM := generateTestCert(t)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, []byte(certPEM), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte(keyPEM), 0o600))
+
+	t.Run("Default", func(t *testing.T) {
+		cl := New(&Config{URL: "ldaps://dc:636"})
+		tc, err := cl.tlsConfig(cl.Config.URL)
+		require.NoError(t, err)
+		require.Equal(t, "dc", tc.ServerName)
+		require.Equal(t, uint16(tls.VersionTLS12), tc.MinVersion)
+		require.False(t, tc.InsecureSkipVerify)
+		require.Nil(t, tc.RootCAs)
+		require.Empty(t, tc.Certificates)
+	})
+	t.Run("Insecure", func(t *testing.T) {
+		cl := New(&Config{URL: "ldaps://dc:636", InsecureTLS: true})
+		tc, err := cl.tlsConfig(cl.Config.URL)
+		require.NoError(t, err)
+		require.True(t, tc.InsecureSkipVerify)
+	})
+	t.Run("Files", func(t *testing.T) {
+		cl := New(&Config{URL: "ldaps://10.0.0.1:636", TLS: &TLSConfig{
+			CAFile:     certFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "dc.company.com",
+			MinVersion: "1.3",
+		}})
+		tc, err := cl.tlsConfig(cl.Config.URL)
+		require.NoError(t, err)
+		require.NotNil(t, tc.RootCAs)
+		require.Len(t, tc.Certificates, 1)
+		require.Equal(t, "dc.company.com", tc.ServerName)
+		require.Equal(t, uint16(tls.VersionTLS13), tc.MinVersion)
+	})
+	t.Run("PEM", func(t *testing.T) {
+		cl := New(&Config{URL: "ldaps://dc:636", TLS: &TLSConfig{CA: certPEM, Cert: certPEM, Key: keyPEM}})
+		tc, err := cl.tlsConfig(cl.Config.URL)
+		require.NoError(t, err)
+		require.NotNil(t, tc.RootCAs)
+		require.Len(t, tc.Certificates, 1)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		for name, cfg := range map[string]*TLSConfig{
+			"MissingCAFile": {CAFile: filepath.Join(dir, "missing.pem")},
+			"EmptyCAFile":   {CAFile: keyFile},
+			"BadCA":         {CA: "bad"},
+			"MissingKey":    {CertFile: certFile},
+			"BadCert":       {Cert: "bad", Key: keyPEM},
+			"BadVersion":    {MinVersion: "2.0"},
+		} {
+			cl := newMockClient(&Config{URL: "ldaps://dc:636", TLS: cfg})
+			_, err := cl.tlsConfig(cl.Config.URL)
+			require.Error(t, err, name)
+		}
+	})
+	t.Run("WithTLSConfig", func(t *testing.T) {
+		base := &tls.Config{ServerName: "custom", MinVersion: tls.VersionTLS11}
+		cl := New(&Config{URL: "ldaps://dc:636", TLS: &TLSConfig{MinVersion: "1.3"}}, WithTLSConfig(base))
+		tc, err := cl.tlsConfig(cl.Config.URL)
+		require.NoError(t, err)
+		require.Equal(t, "custom", tc.ServerName)
+		require.Equal(t, uint16(tls.VersionTLS13), tc.MinVersion)
+		require.Equal(t, uint16(tls.VersionTLS11), base.MinVersion, "Base config should not be modified")
+	})
+	t.Run("StartTLSErr", func(t *testing.T) {
+		cl := newMockClient(&Config{
+			URL:     "ldap://dc:389",
+			TLSMode: TLSModeStartTLSRequired,
+			TLS:     &TLSConfig{CA: "bad"},
+		})
+		require.Error(t, cl.Connect())
+		require.Error(t, cl.CheckAuthByDN(validMockBind.DN, validMockBind.Password))
+	})
+}
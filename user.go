@@ -1,8 +1,12 @@
 package adc
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 )
@@ -19,6 +23,10 @@ type User struct {
 type UserGroup struct {
 	DN string `json:"dn"`
 	Id string `json:"id"`
+	// User is member of the group through nested groups only.
+	Inherited bool `json:"inherited"`
+	// The group is user primary group set by primaryGroupID attribute.
+	Primary bool `json:"primary"`
 }
 
 // Returns string attribute by attribute name.
@@ -34,27 +42,89 @@ func (u *User) GetStringAttribute(name string) string {
 	return ""
 }
 
+// Returns time attribute in FILETIME or GeneralizedTime format by attribute name.
+// Returns zero time if attribute not exists, can't be parsed or means never.
+func (u *User) GetTimeAttribute(name string) time.Time {
+	return ParseTime(stringAttribute(u.Attributes, name))
+}
+
+// Returns objectGUID in canonical form. Attribute must be requested.
+func (u *User) GUID() string {
+	return stringAttribute(u.Attributes, "objectGUID")
+}
+
+// Returns objectSid in 'S-1-5-...' form. Attribute must be requested.
+func (u *User) SID() string {
+	return stringAttribute(u.Attributes, "objectSid")
+}
+
+// Returns userAccountControl flags. Attribute must be requested.
+func (u *User) UserAccountControl() UACFlags {
+	return UACFlags(intAttribute(u.Attributes, "userAccountControl"))
+}
+
+// Returns pwdLastSet time. Zero time means password must be changed at next logon.
+func (u *User) PasswordLastSet() time.Time {
+	return u.GetTimeAttribute("pwdLastSet")
+}
+
+// Returns lastLogonTimestamp time. The value is replicated with a delay of up to two weeks.
+func (u *User) LastLogon() time.Time {
+	return u.GetTimeAttribute("lastLogonTimestamp")
+}
+
+// Returns accountExpires time. Zero time means account never expires.
+func (u *User) AccountExpires() time.Time {
+	return u.GetTimeAttribute("accountExpires")
+}
+
+// Returns whenCreated time.
+func (u *User) WhenCreated() time.Time {
+	return u.GetTimeAttribute("whenCreated")
+}
+
+// Returns whenChanged time.
+func (u *User) WhenChanged() time.Time {
+	return u.GetTimeAttribute("whenChanged")
+}
+
 type GetUserArgs struct {
 	// User ID to search.
 	Id string `json:"id"`
 	// Optional User DN. Overwrites ID if provided in request.
 	Dn string `json:"dn"`
+	// Optional objectGUID in canonical form. Overwrites ID if provided in request and survives renames and moves.
+	GUID string `json:"guid"`
+	// Optional objectSid in 'S-1-5-...' form. Overwrites ID if provided in request.
+	SID string `json:"sid"`
+	// Optional userPrincipalName. Overwrites ID if provided in request.
+	UPN string `json:"upn"`
+	// Optional mail. Overwrites ID if provided in request.
+	Mail string `json:"mail"`
 	// Optional LDAP filter to search entry. Warning! provided Filter arg overwrites Id and Dn args usage.
 	Filter string `json:"filter"`
 	// Optional user attributes to overwrite attributes in client config.
 	Attributes []string `json:"attributes"`
 	// Skip search of user groups data. Can improve request time.
 	SkipGroupsSearch bool `json:"skip_groups_search"`
+	// Include groups user is member of through nested groups. Requires additional request.
+	IncludeNestedGroups bool `json:"include_nested_groups"`
 }
 
 func (args GetUserArgs) Validate() error {
-	if args.Id == "" && args.Dn == "" && args.Filter == "" {
-		return errors.New("neither of ID, DN or Filter provided")
+	if args.Id == "" && args.Dn == "" && args.Filter == "" &&
+		args.GUID == "" && args.SID == "" && args.UPN == "" && args.Mail == "" {
+		return errors.New("neither of ID, DN, GUID, SID, UPN, Mail or Filter provided")
 	}
 	return nil
 }
 
 func (cl *Client) ListUsers(args GetUserArgs, pageSize int, filter string) (*[]User, error) {
+	return cl.ListUsersContext(context.Background(), args, pageSize, filter)
+}
+
+// Context-aware version of ListUsers.
+func (cl *Client) ListUsersContext(ctx context.Context, args GetUserArgs, pageSize int, filter string) (*[]User, error) {
 	req := &ldap.SearchRequest{
 		BaseDN:       cl.Config.Users.SearchBase,
 		Scope:        ldap.ScopeWholeSubtree,
@@ -70,33 +140,9 @@ func (cl *Client) ListUsers(args GetUserArgs, pageSize int, filter string) (*[]U
 		req.Filter = filter
 	}
 
-	control := ldap.NewControlPaging(uint32(pageSize))
-	var entries []*ldap.Entry
-
-	for {
-		req.Controls = []ldap.Control{control}
-
-		sr, err := cl.ldap.Search(req)
-		if err != nil {
-			return nil, err
-		}
-
-		entries = append(entries, sr.Entries...)
-
-		if sr.Controls == nil {
-			break
-		}
-
-		pagingControl, ok := sr.Controls[0].(*ldap.ControlPaging)
-		if !ok {
-			break
-		}
-
-		if len(pagingControl.Cookie) == 0 {
-			break
-		}
-
-		control.SetCookie(pagingControl.Cookie)
+	entries, err := cl.searchPaged(ctx, req, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	if entries == nil {
@@ -108,10 +154,7 @@ func (cl *Client) ListUsers(args GetUserArgs, pageSize int, filter string) (*[]U
 		result := &User{
 			DN:         entry.DN,
 			Id:         entry.GetAttributeValue(cl.Config.Users.IdAttribute),
-			Attributes: make(map[string]interface{}, len(entry.Attributes)),
-		}
-		for _, a := range entry.Attributes {
-			result.Attributes[a.Name] = entry.GetAttributeValue(a.Name)
+			Attributes: entryAttributes(entry),
 		}
 		results = append(results, *result)
 	}
@@ -119,6 +162,11 @@ func (cl *Client) ListUsers(args GetUserArgs, pageSize int, filter string) (*[]U
 }
 
 func (cl *Client) GetUser(args GetUserArgs) (*User, error) {
+	return cl.GetUserContext(context.Background(), args)
+}
+
+// Context-aware version of GetUser.
+func (cl *Client) GetUserContext(ctx context.Context, args GetUserArgs) (*User, error) {
 	if err := args.Validate(); err != nil {
 		return nil, err
 	}
@@ -128,6 +176,13 @@ func (cl *Client) GetUser(args GetUserArgs) (*User, error) {
 		filter = args.Filter
 	} else {
 		filter = fmt.Sprintf(cl.Config.Users.FilterById, args.Id)
+		lookup, err := lookupFilter(cl.Config.Users.FilterByPerson, args.GUID, args.SID, args.UPN, args.Mail)
+		if err != nil {
+			return nil, err
+		}
+		if lookup != "" {
+			filter = lookup
+		}
 		if args.Dn != "" {
 			filter = fmt.Sprintf(cl.Config.Users.FilterByDn, ldap.EscapeFilter(args.Dn))
 		}
@@ -145,7 +200,7 @@ func (cl *Client) GetUser(args GetUserArgs) (*User, error) {
 		req.Attributes = args.Attributes
 	}
 
-	entry, err := cl.searchEntry(req)
+	entry, err := cl.searchEntry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -156,16 +211,13 @@ func (cl *Client) GetUser(args GetUserArgs) (*User, error) {
 	result := &User{
 		DN:         entry.DN,
 		Id:         entry.GetAttributeValue(cl.Config.Users.IdAttribute),
-		Attributes: make(map[string]interface{}, len(entry.Attributes)),
-	}
-	for _, a := range entry.Attributes {
-		result.Attributes[a.Name] = entry.GetAttributeValue(a.Name)
+		Attributes: entryAttributes(entry),
 	}
 
 	if !args.SkipGroupsSearch {
-		groups, err := cl.getUserGroups(entry.DN)
+		groups, err := cl.getUserGroups(ctx, entry.DN, args.IncludeNestedGroups)
 		if err != nil {
-			return nil, fmt.Errorf("can't get user groups: %s", err.Error())
+			return nil, fmt.Errorf("can't get user groups: %w", err)
 		}
 		result.Groups = groups
 	}
@@ -174,47 +226,109 @@ func (cl *Client) GetUser(args GetUserArgs) (*User, error) {
 }
 
 func (cl *Client) CreateUser(dn string, userAttrs []ldap.Attribute) error {
+	return cl.CreateUserContext(context.Background(), dn, userAttrs)
+}
+
+// Context-aware version of CreateUser.
+func (cl *Client) CreateUserContext(ctx context.Context, dn string, userAttrs []ldap.Attribute) error {
 	addReq := ldap.NewAddRequest(dn, []ldap.Control{})
 	addReq.Attributes = userAttrs
 
-	return cl.addRequest(addReq)
+	return cl.addRequest(ctx, addReq)
 }
 
 func (cl *Client) DeleteUser(dn string) error {
+	return cl.DeleteUserContext(context.Background(), dn)
+}
+
+// Context-aware version of DeleteUser.
+func (cl *Client) DeleteUserContext(ctx context.Context, dn string) error {
 	delReq := ldap.NewDelRequest(dn, []ldap.Control{})
 
-	return cl.deleteRequest(delReq)
+	return cl.deleteRequest(ctx, delReq)
 }
 
 func (cl *Client) SetPassword(dn string, newPassword string, mustChange bool) error {
-	err := cl.modifyPassword(dn, newPassword)
+	return cl.SetPasswordContext(context.Background(), dn, newPassword, mustChange)
+}
+
+// Context-aware version of SetPassword.
+func (cl *Client) SetPasswordContext(ctx context.Context, dn string, newPassword string, mustChange bool) error {
+	err := cl.modifyPassword(ctx, dn, newPassword)
 	if err != nil {
 		return err
 	}
 	if !mustChange {
 		return nil
 	}
-	return cl.updateAttribute(dn, "pwdLastSet", []string{"0"})
+	return cl.updateAttribute(ctx, dn, "pwdLastSet", []string{"0"})
+}
+
+// Changes user password after the old password is verified by AD.
+// Returns ErrWrongPassword or ErrPasswordRestriction wrapped with AD error if change is rejected.
+func (cl *Client) ChangePassword(dn string, oldPassword, newPassword string) error {
+	return cl.ChangePasswordContext(context.Background(), dn, oldPassword, newPassword)
+}
+
+// Context-aware version of ChangePassword.
+func (cl *Client) ChangePasswordContext(ctx context.Context, dn string, oldPassword, newPassword string) error {
+	return cl.changePassword(ctx, dn, oldPassword, newPassword)
 }
 
 func (cl *Client) UpdateUser(dn string, userAttrs []ldap.Attribute) error {
+	return cl.UpdateUserContext(context.Background(), dn, userAttrs)
+}
+
+// Context-aware version of UpdateUser.
+func (cl *Client) UpdateUserContext(ctx context.Context, dn string, userAttrs []ldap.Attribute) error {
 	modReq := ldap.NewModifyRequest(dn, []ldap.Control{})
 	for _, a := range userAttrs {
 		modReq.Replace(a.Type, a.Vals)
 	}
-	return cl.modifyRequest(modReq)
+	return cl.modifyRequest(ctx, modReq)
+}
+
+// Returns direct user groups including primary one, followed by inherited ones if nested groups are requested.
+func (cl *Client) getUserGroups(ctx context.Context, dn string, nested bool) ([]UserGroup, error) {
+	result, err := cl.searchUserGroups(ctx, cl.Config.Users.FilterGroupsByDn, dn)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := cl.getUserPrimaryGroup(ctx, dn)
+	if err != nil {
+		return nil, fmt.Errorf("can't get primary group: %w", err)
+	}
+	if primary != nil {
+		result = append(result, *primary)
+	}
+	if !nested {
+		return result, nil
+	}
+
+	all, err := cl.searchUserGroups(ctx, cl.Config.Users.FilterNestedGroupsByDn, dn)
+	if err != nil {
+		return nil, fmt.Errorf("can't get nested groups: %w", err)
+	}
+	for _, g := range all {
+		if slices.ContainsFunc(result, func(d UserGroup) bool { return strings.EqualFold(d.DN, g.DN) }) {
+			continue
+		}
+		g.Inherited = true
+		result = append(result, g)
+	}
+	return result, nil
 }
 
-func (cl *Client) getUserGroups(dn string) ([]UserGroup, error) {
+func (cl *Client) searchUserGroups(ctx context.Context, filter, dn string) ([]UserGroup, error) {
 	req := &ldap.SearchRequest{
 		BaseDN:       cl.Config.Groups.SearchBase,
 		Scope:        ldap.ScopeWholeSubtree,
 		DerefAliases: ldap.NeverDerefAliases,
 		TimeLimit:    int(cl.Config.Timeout.Seconds()),
-		Filter:       fmt.Sprintf(cl.Config.Users.FilterGroupsByDn, ldap.EscapeFilter(dn)),
+		Filter:       fmt.Sprintf(filter, ldap.EscapeFilter(dn)),
 		Attributes:   []string{cl.Config.Groups.IdAttribute},
 	}
-	entries, err := cl.searchEntries(req)
+	entries, err := cl.searchEntries(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +342,8 @@ func (cl *Client) getUserGroups(dn string) ([]UserGroup, error) {
 	return result, nil
 }
 
+// Reports whether user is member of the group with provided ID.
+// Groups inherited through nesting are checked only if they were requested with IncludeNestedGroups.
 func (u *User) IsGroupMember(groupId string) bool {
 	for _, g := range u.Groups {
 		if g.Id == groupId {
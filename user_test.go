@@ -1,6 +1,7 @@
 package adc
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -125,6 +126,20 @@ func Test_Client_GetUser(t *testing.T) {
 		require.NotNil(t, user.Groups)
 		require.Len(t, user.Groups, 1)
 	})
+	t.Run("NestedGroups", func(t *testing.T) {
+		user, err := cl.GetUser(GetUserArgs{Id: "user1"})
+		require.NoError(t, err)
+		require.False(t, user.IsGroupMember("group3"))
+
+		user, err = cl.GetUser(GetUserArgs{Id: "user1", IncludeNestedGroups: true})
+		require.NoError(t, err)
+		require.Equal(t, []UserGroup{
+			{DN: "OU=group1,DC=company,DC=com", Id: "group1"},
+			{DN: "OU=group3,DC=company,DC=com", Id: "group3", Inherited: true},
+		}, user.Groups)
+		require.True(t, user.IsGroupMember("group1"))
+		require.True(t, user.IsGroupMember("group3"))
+	})
 
 	t.Run("ChPwd", func(t *testing.T) {
 		args := GetUserArgs{Id: "user1", SkipGroupsSearch: true}
@@ -134,6 +149,35 @@ func Test_Client_GetUser(t *testing.T) {
 	})
 }
 
+func Test_Client_UserContext(t *testing.T) {
+	cl := newMockClient(&Config{})
+	require.NoError(t, cl.Connect())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("GetUser", func(t *testing.T) {
+		user, err := cl.GetUserContext(ctx, GetUserArgs{Id: "user1"})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, user)
+	})
+	t.Run("ListUsers", func(t *testing.T) {
+		users, err := cl.ListUsersContext(ctx, GetUserArgs{}, 10, "")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, users)
+	})
+	t.Run("SetPassword", func(t *testing.T) {
+		err := cl.SetPasswordContext(ctx, "OU=user1,DC=company,DC=com", "ZXCVqwwer!@#$1234", false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("Ok", func(t *testing.T) {
+		user, err := cl.GetUserContext(context.Background(), GetUserArgs{Id: "user1"})
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.Len(t, user.Groups, 1)
+	})
+}
+
 func Test_User_IsGroupMember(t *testing.T) {
 	t.Run("EmptyGroups", func(t *testing.T) {
 		u := &User{}